@@ -0,0 +1,141 @@
+// This file implements actors, which serialize the methods invoked on
+// an object.
+
+package goop
+
+import "context"
+import "errors"
+import "sync"
+
+// ErrActorStopped is returned by an attempt to invoke a method on an
+// actor that has been stopped.
+var ErrActorStopped = errors.New("Actor is stopped")
+
+// actorMailboxSize is the number of method invocations an actor can
+// queue before senders block.
+const actorMailboxSize = 256
+
+// An Actor owns an object whose methods are invoked one at a time, in
+// the order requested, by a goroutine dedicated to the actor.  Because
+// only that goroutine runs the object's methods, the methods can freely
+// read and modify the object's members without races among
+// themselves.  An Actor is safe for concurrent use by multiple
+// goroutines.
+type Actor struct {
+	obj      Object            // Object whose methods the actor invokes
+	mailbox  chan actorMessage // Queue of pending method invocations
+	stopping chan struct{}     // Channel closed when the actor is stopped
+	done     chan struct{}     // Channel closed when the actor's goroutine exits
+	senders  sync.WaitGroup    // Calls in the midst of queuing an invocation
+	stopped  bool              // true if the actor accepts no more invocations
+	lock     sync.RWMutex      // Lock protecting stopped and additions to senders
+}
+
+// An actorMessage requests the invocation of a method by an actor.
+type actorMessage struct {
+	ctx        context.Context // Context of the invocation or nil for none
+	methodName string          // Name of the method to invoke
+	arguments  []interface{}   // Arguments to pass to the method
+	future     *Future         // Future to complete with the method's results
+}
+
+// NewActor creates an object as by New and returns an Actor that owns
+// it.  The constructor, if any, runs before NewActor returns.  Code
+// outside the object's methods should interact with the object only
+// via the Actor.
+func NewActor(constructor ...interface{}) *Actor {
+	a := &Actor{
+		obj:      New(constructor...),
+		mailbox:  make(chan actorMessage, actorMailboxSize),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// run invokes each queued method in turn until the actor is stopped.
+func (a *Actor) run() {
+	defer close(a.done)
+	for msg := range a.mailbox {
+		if msg.ctx != nil {
+			msg.future.complete(a.obj.CallContext(msg.ctx, msg.methodName, msg.arguments...))
+		} else {
+			msg.future.complete(a.obj.CallErr(msg.methodName, msg.arguments...))
+		}
+	}
+}
+
+// Call queues the invocation of one of the object's methods and
+// returns a Future for its results.  The method is invoked as by
+// CallErr once all previously queued methods have completed.  Call
+// blocks if the actor's queue is full.  Hence, a method that invokes
+// Call on its own actor must not wait for the resulting Future, as the
+// actor cannot run the new method until the current one returns.  If
+// the actor has been stopped, the Future reports ErrActorStopped.
+func (a *Actor) Call(methodName string, arguments ...interface{}) *Future {
+	return a.send(actorMessage{methodName: methodName, arguments: arguments})
+}
+
+// CallContext is like Call but invokes the method as by
+// Object.CallContext.  If ctx is canceled while the actor's queue is
+// full or before the method's turn comes, the method is not invoked,
+// and the Future reports ctx.Err().
+func (a *Actor) CallContext(ctx context.Context, methodName string, arguments ...interface{}) *Future {
+	return a.send(actorMessage{ctx: ctx, methodName: methodName, arguments: arguments})
+}
+
+// send queues a message and returns its Future.  The lock is not held
+// while waiting for room in the mailbox, so a method running on the
+// actor can call Stop while other callers wait.
+func (a *Actor) send(msg actorMessage) *Future {
+	msg.future = newFuture()
+	a.lock.RLock()
+	if a.stopped {
+		a.lock.RUnlock()
+		msg.future.complete(nil, ErrActorStopped)
+		return msg.future
+	}
+	a.senders.Add(1)
+	a.lock.RUnlock()
+	defer a.senders.Done()
+	var canceled <-chan struct{}
+	if msg.ctx != nil {
+		canceled = msg.ctx.Done()
+	}
+	select {
+	case a.mailbox <- msg:
+	case <-a.stopping:
+		msg.future.complete(nil, ErrActorStopped)
+	case <-canceled:
+		msg.future.complete(nil, msg.ctx.Err())
+	}
+	return msg.future
+}
+
+// Stop prevents the actor from accepting further method invocations.
+// Methods already queued still run; the channel returned by Done is
+// closed once they complete.  Calls still waiting for room in the
+// actor's queue report ErrActorStopped.  Stopping a stopped actor has
+// no effect.  A method running on the actor may stop it.
+func (a *Actor) Stop() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.stopped {
+		return
+	}
+	a.stopped = true
+	close(a.stopping)
+
+	// Close the mailbox once no call can send to it.
+	go func() {
+		a.senders.Wait()
+		close(a.mailbox)
+	}()
+}
+
+// Done returns a channel that is closed when the actor has stopped and
+// finished running all of its queued methods.
+func (a *Actor) Done() <-chan struct{} {
+	return a.done
+}
@@ -0,0 +1,119 @@
+// This file tests actors.
+
+package goop_test
+
+import (
+	"context"
+	"errors"
+	"github.com/lanl/goop"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Define a counter whose increment method is not itself atomic.
+func counter(this goop.Object) {
+	this.Set("count", 0)
+	this.Set("increment", func(this goop.Object) int {
+		count := this.Get("count").(int) + 1
+		this.Set("count", count)
+		return count
+	})
+}
+
+// Test that an actor serializes concurrently requested method calls.
+func TestActor(t *testing.T) {
+	const numCalls = 1000
+	a := goop.NewActor(counter)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numCalls/4; j++ {
+				a.Call("increment")
+			}
+		}()
+	}
+	wg.Wait()
+	results, err := a.Call("increment").Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0] != numCalls+1 {
+		t.Fatalf("Expected %d but saw %v", numCalls+1, results[0])
+	}
+}
+
+// Test stopping an actor.
+func TestActorStop(t *testing.T) {
+	a := goop.NewActor(counter)
+	f := a.Call("increment")
+	a.Stop()
+	a.Stop()
+	<-a.Done()
+	if results, err := f.Wait(); err != nil || results[0] != 1 {
+		t.Fatalf("Expected %d but saw %v (%v)", 1, results, err)
+	}
+	if _, err := a.Call("increment").Wait(); err != goop.ErrActorStopped {
+		t.Fatalf("Expected %v but saw %v", goop.ErrActorStopped, err)
+	}
+}
+
+// Test that a canceled context prevents a queued method from running.
+func TestActorCallContext(t *testing.T) {
+	release := make(chan struct{})
+	a := goop.NewActor(func(this goop.Object) {
+		counter(this)
+		this.Set("block", func(this goop.Object) { <-release })
+	})
+	defer a.Stop()
+	a.CallContext(context.Background(), "increment")
+	a.Call("block")
+	ctx, cancel := context.WithCancel(context.Background())
+	f := a.CallContext(ctx, "increment")
+	cancel()
+	close(release)
+	if _, err := f.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v but saw %v", context.Canceled, err)
+	}
+	if results, _ := a.Call("increment").Wait(); results[0] != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, results[0])
+	}
+}
+
+// Test that a method can stop its own actor while other callers wait
+// for room in the actor's queue.
+func TestActorStopFromMethod(t *testing.T) {
+	release := make(chan struct{})
+	var actor *goop.Actor
+	actor = goop.NewActor(func(this goop.Object) {
+		this.Set("block", func(this goop.Object) {
+			<-release
+			actor.Stop()
+		})
+		this.Set("noop", func(this goop.Object) {})
+	})
+	actor.Call("block")
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	stopped := 0
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := actor.Call("noop").Wait(); errors.Is(err, goop.ErrActorStopped) {
+				lock.Lock()
+				stopped++
+				lock.Unlock()
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	<-actor.Done()
+	if stopped == 0 {
+		t.Fatalf("Expected some calls to report %v", goop.ErrActorStopped)
+	}
+}
@@ -0,0 +1,217 @@
+// This file implements adapters that let objects satisfy ordinary Go
+// interfaces.
+
+package goop
+
+import "errors"
+import "fmt"
+import "io"
+import "reflect"
+import "sort"
+import "sync"
+
+// ErrNoAdapter is returned by an attempt to adapt an object to an
+// interface for which no adapter is registered.
+var ErrNoAdapter = errors.New("No adapter is registered for the interface")
+
+// adapters maps an interface type to a function that wraps an object
+// in a value satisfying that interface.
+var adapters = map[reflect.Type]func(Object) interface{}{}
+
+// adaptersLock protects adapters.
+var adaptersLock sync.RWMutex
+
+// RegisterAdapter makes Implement able to adapt objects to interface
+// type T.  Because Go cannot define new methods at run time, each
+// interface requires a small adapter type whose methods forward to
+// the object, as in
+//
+//	type shapeAdapter struct{ obj goop.Object }
+//
+//	func (a shapeAdapter) Area() float64 {
+//	        return a.obj.Call("Area")[0].(float64)
+//	}
+//
+//	goop.RegisterAdapter(func(obj goop.Object) Shape { return shapeAdapter{obj} })
+//
+// Adapters for fmt.Stringer, io.Reader, io.Writer, io.Closer, and
+// sort.Interface are registered automatically.  RegisterAdapter
+// replaces any adapter previously registered for T and panics if T is
+// not an interface type.
+func RegisterAdapter[T any](adapt func(obj Object) T) {
+	ifaceType := reflect.TypeOf((*T)(nil)).Elem()
+	if ifaceType.Kind() != reflect.Interface {
+		panic(fmt.Errorf("RegisterAdapter requires an interface type, not %v", ifaceType))
+	}
+	adaptersLock.Lock()
+	adapters[ifaceType] = func(obj Object) interface{} { return adapt(obj) }
+	adaptersLock.Unlock()
+}
+
+// Implement returns a value that satisfies interface type T by
+// invoking the corresponding methods of an object via Call.  Implement
+// supports only interfaces that have a registered adapter: the
+// interfaces listed under RegisterAdapter and any others registered
+// with it.  It cannot adapt an object to an arbitrary interface,
+// because Go cannot define new methods at run time.  Implement first
+// checks that the object defines (directly or via a prototype) a
+// method for each of the interface's methods and that each method's
+// parameters and results are compatible with the interface's.
+// Implement returns a *NotFoundError if a method is missing, a
+// *TypeError if a method is incompatible, and an error wrapping
+// ErrNoAdapter if no adapter for T is registered.
+func Implement[T any](obj Object) (T, error) {
+	var zero T
+	ifaceType := reflect.TypeOf((*T)(nil)).Elem()
+	if ifaceType.Kind() != reflect.Interface {
+		return zero, fmt.Errorf("Implement requires an interface type, not %v", ifaceType)
+	}
+	for i := 0; i < ifaceType.NumMethod(); i++ {
+		method := ifaceType.Method(i)
+		member, ok := obj.GetOK(method.Name)
+		if !ok {
+			return zero, &NotFoundError{Member: method.Name, Method: true}
+		}
+		if !implementsMethod(member, method.Type) {
+			return zero, &TypeError{Member: method.Name, Expected: method.Type, Actual: reflect.TypeOf(member)}
+		}
+	}
+	adaptersLock.RLock()
+	adapt, ok := adapters[ifaceType]
+	adaptersLock.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("%w: %v", ErrNoAdapter, ifaceType)
+	}
+	return adapt(obj).(T), nil
+}
+
+// implementsMethod returns true if a method function (or any function
+// combined into a MetaFunction) can be invoked with the arguments of
+// an interface method and returns results the interface method can
+// return.
+func implementsMethod(member interface{}, methodType reflect.Type) bool {
+	var funcTypes []reflect.Type
+	if mf, ok := member.(MetaFunction); ok {
+		d := mf.dispatcher()
+		if d == nil {
+			return true // Opaque MetaFunction; assume the best.
+		}
+		funcTypes = d.functionTypes()
+	} else {
+		funcType := reflect.TypeOf(member)
+		if funcType == nil || funcType.Kind() != reflect.Func {
+			return false
+		}
+		funcTypes = []reflect.Type{funcType}
+	}
+	for _, funcType := range funcTypes {
+		if signatureCompatible(funcType, methodType) {
+			return true
+		}
+	}
+	return false
+}
+
+// signatureCompatible returns true if a function, ignoring an initial
+// Object parameter, accepts the parameters of an interface method and
+// returns its results.
+func signatureCompatible(funcType, methodType reflect.Type) bool {
+	first := 0
+	if funcType.NumIn() > 0 && funcType.In(0) == objectType {
+		first = 1
+	}
+	numParams := methodType.NumIn()
+	if funcType.IsVariadic() {
+		if numParams < funcType.NumIn()-first-1 {
+			return false
+		}
+	} else if numParams != funcType.NumIn()-first {
+		return false
+	}
+	for i := 0; i < numParams; i++ {
+		paramType := methodType.In(i)
+		if methodType.IsVariadic() && i == numParams-1 {
+			paramType = paramType.Elem()
+		}
+		if !paramType.AssignableTo(parameterType(funcType, i+first)) {
+			return false
+		}
+	}
+	if funcType.NumOut() != methodType.NumOut() {
+		return false
+	}
+	for i := 0; i < funcType.NumOut(); i++ {
+		if !funcType.Out(i).AssignableTo(methodType.Out(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// resultError returns a method result as an error, treating nil as a
+// nil error.
+func resultError(result interface{}) error {
+	err, _ := result.(error)
+	return err
+}
+
+// A stringerAdapter adapts an object to fmt.Stringer.
+type stringerAdapter struct{ obj Object }
+
+// String invokes the object's String method.
+func (a stringerAdapter) String() string {
+	return a.obj.Call("String")[0].(string)
+}
+
+// A readerAdapter adapts an object to io.Reader.
+type readerAdapter struct{ obj Object }
+
+// Read invokes the object's Read method.
+func (a readerAdapter) Read(p []byte) (int, error) {
+	results := a.obj.Call("Read", p)
+	return results[0].(int), resultError(results[1])
+}
+
+// A writerAdapter adapts an object to io.Writer.
+type writerAdapter struct{ obj Object }
+
+// Write invokes the object's Write method.
+func (a writerAdapter) Write(p []byte) (int, error) {
+	results := a.obj.Call("Write", p)
+	return results[0].(int), resultError(results[1])
+}
+
+// A closerAdapter adapts an object to io.Closer.
+type closerAdapter struct{ obj Object }
+
+// Close invokes the object's Close method.
+func (a closerAdapter) Close() error {
+	return resultError(a.obj.Call("Close")[0])
+}
+
+// A sortAdapter adapts an object to sort.Interface.
+type sortAdapter struct{ obj Object }
+
+// Len invokes the object's Len method.
+func (a sortAdapter) Len() int {
+	return a.obj.Call("Len")[0].(int)
+}
+
+// Less invokes the object's Less method.
+func (a sortAdapter) Less(i, j int) bool {
+	return a.obj.Call("Less", i, j)[0].(bool)
+}
+
+// Swap invokes the object's Swap method.
+func (a sortAdapter) Swap(i, j int) {
+	a.obj.Call("Swap", i, j)
+}
+
+// init registers adapters for common standard-library interfaces.
+func init() {
+	RegisterAdapter(func(obj Object) fmt.Stringer { return stringerAdapter{obj} })
+	RegisterAdapter(func(obj Object) io.Reader { return readerAdapter{obj} })
+	RegisterAdapter(func(obj Object) io.Writer { return writerAdapter{obj} })
+	RegisterAdapter(func(obj Object) io.Closer { return closerAdapter{obj} })
+	RegisterAdapter(func(obj Object) sort.Interface { return sortAdapter{obj} })
+}
@@ -0,0 +1,79 @@
+// This file tests adapting objects to ordinary Go interfaces.
+
+package goop_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lanl/goop"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Test adapting objects to standard-library interfaces.
+func TestImplement(t *testing.T) {
+	// Sort a list of strings stored in an object.
+	words := []string{"pear", "apple", "fig"}
+	list := goop.New()
+	list.Set("Len", func() int { return len(words) })
+	list.Set("Less", func(this goop.Object, i, j int) bool { return words[i] < words[j] })
+	list.Set("Swap", func(i, j int) { words[i], words[j] = words[j], words[i] })
+	sorter, err := goop.Implement[sort.Interface](list)
+	if err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	sort.Sort(sorter)
+	if result := strings.Join(words, " "); result != "apple fig pear" {
+		t.Fatalf("Expected %q but saw %q", "apple fig pear", result)
+	}
+
+	// Format an object via fmt.Stringer.
+	list.Set("String", func() string { return strings.Join(words, ",") })
+	stringer, err := goop.Implement[fmt.Stringer](list)
+	if err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if result := fmt.Sprint(stringer); result != "apple,fig,pear" {
+		t.Fatalf("Expected %q but saw %q", "apple,fig,pear", result)
+	}
+
+	// Ensure that missing and incompatible methods are reported.
+	if _, err := goop.Implement[fmt.Stringer](goop.New()); !errors.Is(err, goop.ErrNoSuchMethod) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoSuchMethod, err)
+	}
+	list.Set("String", func() int { return 0 })
+	if _, err := goop.Implement[fmt.Stringer](list); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+}
+
+// A Greeter is an interface with no built-in adapter.
+type Greeter interface {
+	Greet(name string) string
+}
+
+// A greeterAdapter adapts an object to a Greeter.
+type greeterAdapter struct{ obj goop.Object }
+
+// Greet invokes the object's Greet method.
+func (a greeterAdapter) Greet(name string) string {
+	return a.obj.Call("Greet", name)[0].(string)
+}
+
+// Test registering an adapter for a user-defined interface.
+func TestRegisterAdapter(t *testing.T) {
+	obj := goop.New()
+	obj.Set("Greet", func(this goop.Object, name string) string { return "Hello, " + name })
+	if _, err := goop.Implement[Greeter](obj); !errors.Is(err, goop.ErrNoAdapter) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoAdapter, err)
+	}
+	goop.RegisterAdapter(func(obj goop.Object) Greeter { return greeterAdapter{obj} })
+	greeter, err := goop.Implement[Greeter](obj)
+	if err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if result := greeter.Greet("world"); result != "Hello, world" {
+		t.Fatalf("Expected %q but saw %q", "Hello, world", result)
+	}
+}
@@ -0,0 +1,73 @@
+// This file implements arenas, which allocate objects in bulk and
+// dispose of them all at once.
+
+package goop
+
+import "sync"
+
+// arenaSlabSize is the number of objects an Arena allocates at a time.
+const arenaSlabSize = 256
+
+// An Arena allocates objects from large slabs of memory rather than
+// individually and invalidates them all at once when freed.  This
+// reduces allocation and garbage-collection overhead for programs
+// that create many objects with a common lifetime, such as the
+// objects of one simulation timestep.  An Arena is safe for concurrent
+// use by multiple goroutines.
+type Arena struct {
+	lock sync.Mutex  // Lock protecting all of the following
+	slab []internal  // Unused portion of the current slab
+	used []*internal // Objects allocated since the arena was created or last freed
+}
+
+// NewArena returns a new, empty arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// New is like the package-level New but allocates the object from the
+// arena.
+func (a *Arena) New(constructor ...interface{}) Object {
+	a.lock.Lock()
+	if len(a.slab) == 0 {
+		a.slab = make([]internal, arenaSlabSize)
+	}
+	impl := &a.slab[0]
+	a.slab = a.slab[1:]
+	a.used = append(a.used, impl)
+	a.lock.Unlock()
+	impl.id = newID()
+	impl.inArena = true
+	impl.symbolTable = newMemberTable()
+	return construct(Object{Implementation: impl}, constructor)
+}
+
+// Len returns the number of objects allocated from the arena since it
+// was created or last freed.
+func (a *Arena) Len() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return len(a.used)
+}
+
+// Free invalidates every object allocated from the arena since it was
+// created or last freed.  An invalidated object has no members or
+// prototypes and is frozen, so attempts to modify it fail with
+// ErrFrozen.  The arena's memory is reclaimed by the garbage collector
+// once no invalidated objects remain reachable, and the arena can
+// continue to allocate new objects.  Finalize methods are not
+// invoked.  Because an arena's objects share storage, EnableFinalizer
+// has no effect on them.
+func (a *Arena) Free() {
+	a.lock.Lock()
+	used := a.used
+	a.used = nil
+	a.slab = nil
+	a.lock.Unlock()
+	for _, impl := range used {
+		impl.lock.Lock()
+		impl.reset()
+		impl.frozen = true
+		impl.lock.Unlock()
+	}
+}
@@ -0,0 +1,71 @@
+// This file tests allocating objects from arenas.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test allocating objects from an arena and freeing them in bulk.
+func TestArena(t *testing.T) {
+	// Allocate more than one slab's worth of objects.
+	arena := goop.NewArena()
+	proto := goop.New()
+	proto.Set("mass", 1.0)
+	const numObjs = 1000
+	objs := make([]goop.Object, numObjs)
+	for i := range objs {
+		objs[i] = arena.New(func(this goop.Object, x int) {
+			this.SetSuper(proto)
+			this.Set("x", x)
+		}, i)
+	}
+	if arena.Len() != numObjs {
+		t.Fatalf("Expected %d but saw %d", numObjs, arena.Len())
+	}
+	if result := objs[numObjs-1].Get("x").(int); result != numObjs-1 {
+		t.Fatalf("Expected %d but saw %v", numObjs-1, result)
+	}
+	if result := objs[0].Get("mass").(float64); result != 1.0 {
+		t.Fatalf("Expected %.1f but saw %v", 1.0, result)
+	}
+
+	// Ensure that freeing the arena invalidates its objects.
+	arena.Free()
+	if objs[0].HasMember("x") || objs[0].HasMember("mass") {
+		t.Fatalf("Expected a freed object to have no members")
+	}
+	if err := objs[0].TrySet("x", 0); err != goop.ErrFrozen {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, err)
+	}
+	if arena.Len() != 0 {
+		t.Fatalf("Expected %d but saw %d", 0, arena.Len())
+	}
+}
+
+// Test finalizing and releasing objects that do not begin an arena's
+// slab.
+func TestArenaFinalize(t *testing.T) {
+	arena := goop.NewArena()
+	arena.New()
+	finalized := false
+	obj := arena.New()
+	obj.Set(goop.Finalize, func(this goop.Object) { finalized = true })
+	obj.EnableFinalizer()
+	if err := obj.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+	if !finalized {
+		t.Fatalf("Expected %v but saw %v", true, finalized)
+	}
+	var pool goop.Pool
+	other := arena.New()
+	other.EnableFinalizer()
+	pool.Release(other)
+	reused := pool.Acquire()
+	reused.EnableFinalizer()
+	if err := reused.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+}
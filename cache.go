@@ -0,0 +1,133 @@
+// This file implements caching of the results of searching an object's
+// prototypes for a member.
+
+package goop
+
+import "sync/atomic"
+
+// Searching a deep prototype hierarchy for an inherited member
+// requires locking and searching each ancestor in turn.  To avoid
+// repeating that work, each object caches the ancestor (if any) that
+// provides each inherited member it has looked up.  A cache is valid
+// only as long as the object's own list of prototypes and the global
+// lookup epoch are unchanged.  The epoch is advanced whenever an object
+// that serves as a prototype gains or loses a member or changes its
+// own list of prototypes, as either can change where a descendant's
+// members are found.  Changes to objects that are not prototypes
+// affect no other object's cache and so do not advance the epoch.
+
+// lookupEpoch is the global generation number of all lookup caches.
+var lookupEpoch uint64
+
+// maxCachedLookups is the largest number of member names for which an
+// object caches the result of a prototype search.
+const maxCachedLookups = 64
+
+// A lookupCache maps names of inherited members to the ancestors that
+// provide them.  A lookupCache is immutable once stored in an object.
+type lookupCache struct {
+	epoch  uint64            // Value of lookupEpoch when the cache was valid
+	gen    uint64            // Value of the object's protoGen when the cache was valid
+	owners map[string]Object // Map from a member name to its owner, or to Object{} if not found
+}
+
+// markPrototypes records that each of a list of objects serves as a
+// prototype.  It must be called before the objects are made prototypes.
+func markPrototypes(prototypes []Object) {
+	for _, proto := range prototypes {
+		proto.Implementation.isPrototype.Store(true)
+	}
+}
+
+// invalidateLookups invalidates every object's lookup cache if the
+// object serves as a prototype.  The caller must hold the object's
+// lock and must call invalidateLookups after adding or removing one of
+// the object's members.
+func (impl *internal) invalidateLookups() {
+	if impl.isPrototype.Load() {
+		atomic.AddUint64(&lookupEpoch, 1)
+	}
+}
+
+// prototypesChanged invalidates the lookup caches affected by a change
+// to the object's list of prototypes.  The caller must hold the
+// object's lock and must call prototypesChanged after modifying the
+// list.
+func (impl *internal) prototypesChanged() {
+	impl.discardView()
+	impl.protoGen++
+	impl.invalidateLookups()
+}
+
+// lookupInherited searches an object's prototypes, which the caller
+// read together with gen, the object's protoGen, for a member and
+// returns the first value found, as evaluated on this, the object
+// itself.
+func (impl *internal) lookupInherited(this Object, prototypes []Object, gen uint64, memberName string) (interface{}, bool) {
+	if len(prototypes) == 0 {
+		return nil, false
+	}
+
+	// Consult the cache.  A cached owner that no longer contains the
+	// member is being modified concurrently; search as if uncached.
+	epoch := atomic.LoadUint64(&lookupEpoch)
+	loaded := impl.lookups.Load()
+	cache := loaded
+	if cache != nil && cache.epoch == epoch && cache.gen == gen {
+		if owner, ok := cache.owners[memberName]; ok {
+			if owner.Implementation == nil {
+				return nil, false
+			}
+			ownerImpl := owner.Implementation
+			ownerImpl.lock.RLock()
+			stored, found := ownerImpl.symbolTable.get(memberName)
+			ownerImpl.lock.RUnlock()
+			if found {
+				return inheritedValue(this, owner, stored), true
+			}
+		}
+	} else {
+		cache = nil
+	}
+
+	// Search each parent in turn and cache the result.
+	var owner Object
+	var stored interface{}
+	var found bool
+	for _, parent := range prototypes {
+		if owner, stored, found = parent.resolveStored(memberName); found {
+			break
+		}
+	}
+	impl.cacheLookup(loaded, cache, epoch, gen, memberName, owner)
+	if !found {
+		return nil, false
+	}
+	return inheritedValue(this, owner, stored), true
+}
+
+// cacheLookup adds a member's owner to the object's lookup cache.
+// loaded is the cache as previously loaded from the object, and old is
+// the same cache if valid or nil if not.  The owner is not cached if
+// the cache is full or was replaced concurrently.
+func (impl *internal) cacheLookup(loaded, old *lookupCache, epoch, gen uint64, memberName string, owner Object) {
+	size := 1
+	if old != nil {
+		size += len(old.owners)
+	}
+	if size > maxCachedLookups {
+		return
+	}
+	cache := &lookupCache{
+		epoch:  epoch,
+		gen:    gen,
+		owners: make(map[string]Object, size),
+	}
+	if old != nil {
+		for name, o := range old.owners {
+			cache.owners[name] = o
+		}
+	}
+	cache.owners[memberName] = owner
+	impl.lookups.CompareAndSwap(loaded, cache)
+}
@@ -0,0 +1,90 @@
+// This file tests caching of inherited-member lookups.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that cached lookups observe changes to ancestors' members.
+func TestLookupCacheMembers(t *testing.T) {
+	grandparent := goop.New()
+	grandparent.Set("name", "grandparent")
+	parent := goop.New()
+	parent.SetSuper(grandparent)
+	child := goop.New()
+	child.SetSuper(parent)
+	for i := 0; i < 2; i++ {
+		if result := child.Get("name"); result != "grandparent" {
+			t.Fatalf("Expected %q but saw %v", "grandparent", result)
+		}
+	}
+	parent.Set("name", "parent")
+	if result := child.Get("name"); result != "parent" {
+		t.Fatalf("Expected %q but saw %v", "parent", result)
+	}
+	parent.Unset("name")
+	if result := child.Get("name"); result != "grandparent" {
+		t.Fatalf("Expected %q but saw %v", "grandparent", result)
+	}
+	grandparent.Set("name", "changed")
+	if result := child.Get("name"); result != "changed" {
+		t.Fatalf("Expected %q but saw %v", "changed", result)
+	}
+	grandparent.Unset("name")
+	if result := child.Get("name"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+
+	// Previously missing members must be found once they are added.
+	grandparent.Set("age", 80)
+	if result := child.Get("age"); result != 80 {
+		t.Fatalf("Expected %d but saw %v", 80, result)
+	}
+}
+
+// Test that cached lookups observe changes to prototype lists.
+func TestLookupCachePrototypes(t *testing.T) {
+	left := goop.New()
+	left.Set("side", "left")
+	right := goop.New()
+	right.Set("side", "right")
+	middle := goop.New()
+	middle.SetSuper(left)
+	child := goop.New()
+	child.SetSuper(middle)
+	if result := child.Get("side"); result != "left" {
+		t.Fatalf("Expected %q but saw %v", "left", result)
+	}
+	middle.SetSuper(right)
+	if result := child.Get("side"); result != "right" {
+		t.Fatalf("Expected %q but saw %v", "right", result)
+	}
+	child.SetSuper(left)
+	if result := child.Get("side"); result != "left" {
+		t.Fatalf("Expected %q but saw %v", "left", result)
+	}
+	child.AddSuper(right)
+	child.RemoveSuper(left)
+	if result := child.Get("side"); result != "right" {
+		t.Fatalf("Expected %q but saw %v", "right", result)
+	}
+}
+
+// Benchmark method calls through a deep prototype hierarchy.
+func BenchmarkDeepCall(b *testing.B) {
+	base := goop.New()
+	base.Set("answer", func(this goop.Object) int { return 42 })
+	obj := base
+	for i := 0; i < 10; i++ {
+		child := goop.New()
+		child.SetSuper(obj)
+		child.Set("level", i)
+		obj = child
+	}
+	b.ResetTimer()
+	for i := b.N; i > 0; i-- {
+		obj.Call("answer")
+	}
+}
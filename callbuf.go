@@ -0,0 +1,85 @@
+// This file implements reusable buffers for the argument lists built
+// when invoking methods.
+
+package goop
+
+import "reflect"
+import "sync"
+
+// maxPooledArgs is the largest capacity of an argument buffer that is
+// returned to its pool for reuse.  Larger buffers are left to the
+// garbage collector so that a rare call with many arguments does not
+// pin a large buffer indefinitely.
+const maxPooledArgs = 32
+
+// argListPool holds buffers for lists of method arguments.
+var argListPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]interface{}, 0, 8)
+		return &buf
+	},
+}
+
+// valuesPool holds buffers for lists of reflected function arguments.
+var valuesPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]reflect.Value, 0, 8)
+		return &buf
+	},
+}
+
+// getArgList returns an empty buffer from argListPool.  The buffer
+// must be returned with putArgList once it is no longer in use and
+// must never be retained beyond a call.
+func getArgList() *[]interface{} {
+	return argListPool.Get().(*[]interface{})
+}
+
+// putArgList clears a buffer obtained from getArgList and returns it to
+// argListPool.
+func putArgList(buf *[]interface{}) {
+	if cap(*buf) > maxPooledArgs {
+		return
+	}
+	args := *buf
+	for i := range args {
+		args[i] = nil
+	}
+	*buf = args[:0]
+	argListPool.Put(buf)
+}
+
+// withThis returns a buffer from argListPool containing an object
+// followed by a list of arguments.
+func withThis(this Object, arguments []interface{}) *[]interface{} {
+	buf := getArgList()
+	*buf = append(append(*buf, this), arguments...)
+	return buf
+}
+
+// getValues returns a buffer from valuesPool holding n zero Values.
+// The buffer must be returned with putValues once it is no longer in
+// use.
+func getValues(n int) *[]reflect.Value {
+	buf := valuesPool.Get().(*[]reflect.Value)
+	if cap(*buf) < n {
+		*buf = make([]reflect.Value, n)
+	} else {
+		*buf = (*buf)[:n]
+	}
+	return buf
+}
+
+// putValues clears a buffer obtained from getValues and returns it to
+// valuesPool.
+func putValues(buf *[]reflect.Value) {
+	if cap(*buf) > maxPooledArgs {
+		return
+	}
+	values := *buf
+	for i := range values {
+		values[i] = reflect.Value{}
+	}
+	*buf = values[:0]
+	valuesPool.Put(buf)
+}
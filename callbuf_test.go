@@ -0,0 +1,56 @@
+// This file tests the reuse of argument buffers across method calls.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that nested calls through MetaFunctions each see their own
+// arguments.
+func TestNestedCallArguments(t *testing.T) {
+	obj := goop.New()
+	obj.Set("sum", goop.CombineFunctions(
+		func(this goop.Object, x int) int {
+			return x
+		},
+		func(this goop.Object, x, y int) int {
+			return this.Call("sum", x)[0].(int) + this.Call("sum", y)[0].(int)
+		},
+		func(this goop.Object, x, y, z int) int {
+			return this.Call("sum", x, y)[0].(int) + this.Call("sum", z)[0].(int)
+		}))
+	if result := obj.Call("sum", 1, 2, 3)[0]; result != 6 {
+		t.Fatalf("Expected %d but saw %v", 6, result)
+	}
+	if result, err := obj.Call1("sum", 4, 5, 6); err != nil || result != 15 {
+		t.Fatalf("Expected %d but saw %v (%v)", 15, result, err)
+	}
+}
+
+// Test that a variadic method may retain its arguments after the call
+// returns.
+func TestRetainedArguments(t *testing.T) {
+	var retained [][]interface{}
+	obj := goop.New()
+	obj.Set("keep", goop.CombineFunctions(func(this goop.Object, args ...interface{}) {
+		retained = append(retained, args)
+	}))
+	obj.Set("keepMeta", goop.MetaFunction(func(args ...interface{}) []interface{} {
+		retained = append(retained, args[1:])
+		return nil
+	}))
+	obj.Call("keep", 1, 2)
+	obj.Call("keepMeta", 3, 4)
+	obj.Call("keep", 5, 6)
+	obj.Call("keepMeta", 7, 8)
+	if len(retained) != 4 {
+		t.Fatalf("Expected %d but saw %d", 4, len(retained))
+	}
+	for i, args := range retained {
+		if len(args) != 2 || args[0] != 2*i+1 || args[1] != 2*i+2 {
+			t.Fatalf("Expected [%d %d] but saw %v", 2*i+1, 2*i+2, args)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// This file implements computed object members, whose values are
+// derived from other members rather than stored directly.
+
+package goop
+
+import "errors"
+import "sync"
+
+// ErrReadOnly is returned by an attempt to modify a read-only member.
+var ErrReadOnly = errors.New("Member is read-only")
+
+// A computed represents a member whose value is produced by a function
+// and cached until one of the members on which it depends changes.
+type computed struct {
+	deps       []string                      // Names of the members the value depends on
+	compute    func(this Object) interface{} // Function that produces the value
+	lock       sync.Mutex                    // Lock protecting the remaining fields
+	value      interface{}                   // Cached value
+	valid      bool                          // true if value is current
+	generation uint64                        // Number of invalidations to date
+}
+
+// get returns the member's value, recomputing it if necessary.
+func (c *computed) get(this Object) interface{} {
+	// Return the cached value if it's still valid.
+	c.lock.Lock()
+	if c.valid {
+		value := c.value
+		c.lock.Unlock()
+		return value
+	}
+	generation := c.generation
+	c.lock.Unlock()
+
+	// Recompute the value without holding the lock, as compute
+	// will likely Get other members.  Cache the result unless a
+	// dependency changed in the meantime.
+	value := c.compute(this)
+	c.lock.Lock()
+	if c.generation == generation {
+		c.value = value
+		c.valid = true
+	}
+	c.lock.Unlock()
+	return value
+}
+
+// invalidate discards the member's cached value.
+func (c *computed) invalidate() {
+	c.lock.Lock()
+	c.valid = false
+	c.value = nil
+	c.generation++
+	c.lock.Unlock()
+}
+
+// DefineComputed defines a read-only member whose value is produced by
+// calling compute on the object.  The value is cached and recomputed
+// only after one of the members named in deps has been Set or Unset on
+// the object.  (Changes to inherited members do not invalidate the
+// cache.)  A descendant that inherits the member instead calls compute
+// on itself, without caching, each time it reads the member.
+// Attempting to Set a computed member panics with
+// ErrReadOnly; use Unset to remove it.  DefineComputed panics with
+// ErrFrozen if the object is frozen and with ErrSealed if the object
+// is sealed and does not already contain the member.
+func (obj *Object) DefineComputed(name string, deps []string, compute func(this Object) interface{}) {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if err := impl.checkDefine(name); err != nil {
+		panic(err)
+	}
+	impl.recordHistory()
+	impl.removeComputed(name)
+	impl.addComputed(name, deps, compute)
+	impl.memberChanged(name)
+}
+
+// addComputed stores a new computed member and registers its
+// dependencies.  The caller must hold the object's lock.
+func (impl *internal) addComputed(name string, deps []string, compute func(this Object) interface{}) {
+	c := &computed{
+		deps:    append([]string(nil), deps...),
+		compute: compute,
+	}
+	impl.ownTable()
+	impl.symbolTable.put(name, c)
+	impl.invalidateLookups()
+	if impl.dependents == nil {
+		impl.dependents = make(map[string][]*computed)
+	}
+	for _, dep := range c.deps {
+		impl.dependents[dep] = append(impl.dependents[dep], c)
+	}
+}
+
+// removeComputed unregisters a computed member's dependencies if the
+// named member is computed.  The caller must hold the object's lock.
+func (impl *internal) removeComputed(name string) {
+	stored, _ := impl.symbolTable.get(name)
+	c, ok := stored.(*computed)
+	if !ok {
+		return
+	}
+	for _, dep := range c.deps {
+		others := impl.dependents[dep]
+		for i, other := range others {
+			if other == c {
+				others = append(others[:i], others[i+1:]...)
+				break
+			}
+		}
+		if len(others) == 0 {
+			delete(impl.dependents, dep)
+		} else {
+			impl.dependents[dep] = others
+		}
+	}
+}
+
+// memberChanged is invoked whenever a member of the object is Set or
+// Unset.  It invalidates any computed members that depend on the
+// changed member.  The caller must hold the object's lock.
+func (impl *internal) memberChanged(name string) {
+	for _, c := range impl.dependents[name] {
+		c.invalidate()
+	}
+}
@@ -0,0 +1,91 @@
+// This file tests computed object members.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that a computed member is cached until a dependency changes.
+func TestDefineComputed(t *testing.T) {
+	// Define a rectangle with a computed area.
+	rect := goop.New()
+	rect.Set("width", 3)
+	rect.Set("height", 4)
+	numComputations := 0
+	rect.DefineComputed("area", []string{"width", "height"}, func(this goop.Object) interface{} {
+		numComputations++
+		return this.Get("width").(int) * this.Get("height").(int)
+	})
+
+	// Ensure that repeated reads use the cached value.
+	for i := 0; i < 3; i++ {
+		if result := rect.Get("area").(int); result != 12 {
+			t.Fatalf("Expected %d but saw %v", 12, result)
+		}
+	}
+	if numComputations != 1 {
+		t.Fatalf("Expected %d computation but saw %d", 1, numComputations)
+	}
+
+	// Ensure that changing a dependency invalidates the cache.
+	rect.Set("width", 5)
+	if result := rect.Get("area").(int); result != 20 {
+		t.Fatalf("Expected %d but saw %v", 20, result)
+	}
+	rect.Set("color", "red")
+	if result := rect.Contents(false)["area"].(int); result != 20 {
+		t.Fatalf("Expected %d but saw %v", 20, result)
+	}
+	if numComputations != 2 {
+		t.Fatalf("Expected %d computations but saw %d", 2, numComputations)
+	}
+
+	// Ensure that computed members are read-only.
+	defer func() {
+		if r := recover(); r != goop.ErrReadOnly {
+			t.Fatalf("Expected %v but saw %v", goop.ErrReadOnly, r)
+		}
+	}()
+	rect.Set("area", 0)
+}
+
+// Test that inherited computed members and property getters are
+// evaluated on the object that inherits them.
+func TestInheritedComputed(t *testing.T) {
+	proto := goop.New()
+	proto.Set("w", 1)
+	proto.Set("h", 1)
+	proto.DefineComputed("area", []string{"w", "h"}, func(this goop.Object) interface{} {
+		return this.Get("w").(int) * this.Get("h").(int)
+	})
+	proto.DefineProperty("perimeter", goop.Descriptor{
+		Get: func(this goop.Object) interface{} {
+			return 2 * (this.Get("w").(int) + this.Get("h").(int))
+		},
+		Enumerable: true,
+	})
+	child := goop.New()
+	child.SetSuper(proto)
+	child.Set("w", 3)
+	child.Set("h", 4)
+	for i := 0; i < 2; i++ {
+		if area := child.Get("area"); area != 12 {
+			t.Fatalf("Expected %d but saw %v", 12, area)
+		}
+		if perimeter := child.Get("perimeter"); perimeter != 14 {
+			t.Fatalf("Expected %d but saw %v", 14, perimeter)
+		}
+		if area := proto.Get("area"); area != 1 {
+			t.Fatalf("Expected %d but saw %v", 1, area)
+		}
+	}
+	if _, area, _ := child.Resolve("area"); area != 12 {
+		t.Fatalf("Expected %d but saw %v", 12, area)
+	}
+	contents := child.Contents(false)
+	if contents["area"] != 12 || contents["perimeter"] != 14 {
+		t.Fatalf("Expected area 12 and perimeter 14 but saw %v", contents)
+	}
+}
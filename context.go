@@ -0,0 +1,64 @@
+// This file implements method invocation that respects a
+// context.Context.
+
+package goop
+
+import "context"
+import "reflect"
+
+// contextType is the reflected type of a context.Context.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// CallContext is like CallErr but lets the method participate in Go's
+// cancellation model.  If the method's function (or, for a
+// MetaFunction, any of the functions it combines) declares a
+// context.Context as its first parameter following the object itself,
+// ctx is passed as that argument ahead of the given arguments.
+// Otherwise, the arguments are passed as given.  CallContext returns
+// ctx.Err() without invoking the method if ctx is already canceled or
+// past its deadline.  It is up to the method to honor cancellation
+// while it runs.
+func (obj *Object) CallContext(ctx context.Context, methodName string, arguments ...interface{}) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if userFuncIface, ok := obj.GetOK(methodName); ok && wantsContext(userFuncIface) {
+		argList := make([]interface{}, 0, len(arguments)+1)
+		argList = append(argList, ctx)
+		arguments = append(argList, arguments...)
+	}
+	return obj.CallErr(methodName, arguments...)
+}
+
+// wantsContext returns whether a method function (or, for a
+// MetaFunction, any of the functions it combines) declares a
+// context.Context as its first parameter following the object itself.
+func wantsContext(userFuncIface interface{}) bool {
+	if mf, ok := userFuncIface.(MetaFunction); ok {
+		d := mf.dispatcher()
+		if d == nil {
+			return false
+		}
+		d.lock.RLock()
+		targets := d.targets
+		d.lock.RUnlock()
+		for _, t := range targets {
+			if hasContextParam(t.funcType) {
+				return true
+			}
+		}
+		return false
+	}
+	funcType := reflect.TypeOf(userFuncIface)
+	return funcType != nil && funcType.Kind() == reflect.Func && hasContextParam(funcType)
+}
+
+// hasContextParam returns whether a function's first parameter
+// following an optional Object is a context.Context.
+func hasContextParam(funcType reflect.Type) bool {
+	i := 0
+	if funcType.NumIn() > 0 && funcType.In(0) == objectType {
+		i = 1
+	}
+	return funcType.NumIn() > i && funcType.In(i) == contextType
+}
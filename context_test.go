@@ -0,0 +1,40 @@
+// This file tests context-aware method invocation.
+
+package goop_test
+
+import (
+	"context"
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test passing a context to methods that accept one.
+func TestCallContext(t *testing.T) {
+	type key string
+	obj := goop.New()
+	obj.Set("lookup", func(this goop.Object, ctx context.Context, k string) interface{} {
+		return ctx.Value(key(k))
+	})
+	obj.Set("plain", func(this goop.Object, x int) int { return x + 1 })
+	obj.Set("combined", goop.CombineFunctions(
+		func(this goop.Object, ctx context.Context) error { return ctx.Err() },
+		func(this goop.Object, ctx context.Context, x int) int { return x * 2 }))
+	ctx := context.WithValue(context.Background(), key("user"), "alice")
+	if results, err := obj.CallContext(ctx, "lookup", "user"); err != nil || results[0] != "alice" {
+		t.Fatalf("Expected %q but saw %v (%v)", "alice", results, err)
+	}
+	if results, err := obj.CallContext(ctx, "plain", 1); err != nil || results[0] != 2 {
+		t.Fatalf("Expected %d but saw %v (%v)", 2, results, err)
+	}
+	if results, err := obj.CallContext(ctx, "combined", 21); err != nil || results[0] != 42 {
+		t.Fatalf("Expected %d but saw %v (%v)", 42, results, err)
+	}
+
+	// A canceled context prevents the call.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := obj.CallContext(canceled, "plain", 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v but saw %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,217 @@
+// This file implements comparing and merging objects member by member.
+
+package goop
+
+import "fmt"
+import "reflect"
+import "sort"
+import "strings"
+
+// A ChangeKind specifies how a member differs between two objects.
+type ChangeKind int
+
+// These are the kinds of change reported by Diff.
+const (
+	Added    ChangeKind = iota // The member appears only in the second object
+	Removed                    // The member appears only in the first object
+	Modified                   // The member appears in both objects with different values
+)
+
+// String returns the name of a ChangeKind.
+func (kind ChangeKind) String() string {
+	switch kind {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(kind))
+	}
+}
+
+// A Change describes one difference between two objects.
+type Change struct {
+	Kind ChangeKind  // How the member differs
+	Path []string    // Name of the member, preceded by the names of the members containing it for a nested change
+	Old  interface{} // Value in the first object (nil if Added)
+	New  interface{} // Value in the second object (nil if Removed)
+}
+
+// String returns a description of a Change.
+func (c Change) String() string {
+	path := strings.Join(c.Path, ".")
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+%s: %v", path, c.New)
+	case Removed:
+		return fmt.Sprintf("-%s: %v", path, c.Old)
+	default:
+		return fmt.Sprintf("~%s: %v -> %v", path, c.Old, c.New)
+	}
+}
+
+// DiffOptions control the comparison performed by DiffWith.
+type DiffOptions struct {
+	Recursive bool // true to report changes within nested objects rather than the nested objects themselves
+	Methods   bool // true to compare methods as well as data members
+}
+
+// Diff returns the changes that turn object a's data members into
+// object b's, as listed by Contents(false), sorted by member name.
+// Values are compared as by Equal.
+func Diff(a, b Object) []Change {
+	return DiffWith(a, b, DiffOptions{})
+}
+
+// DiffWith is like Diff but accepts options that control the
+// comparison.  With Recursive set, a member whose values in both
+// objects are objects is not reported as Modified but is compared
+// recursively, producing changes whose Paths name the nested members.
+// With Methods set, methods are compared as by EqualWith.
+func DiffWith(a, b Object, opts DiffOptions) []Change {
+	changes := diffObjects(nil, a, b, opts, make(map[[2]*internal]bool))
+	sort.SliceStable(changes, func(i, j int) bool {
+		return lessPath(changes[i].Path, changes[j].Path)
+	})
+	return changes
+}
+
+// diffObjects appends to a list of changes those between two objects,
+// which are found at a given path.  visited records the pairs of
+// objects already compared so that cyclic object graphs terminate.
+func diffObjects(path []string, a, b Object, opts DiffOptions, visited map[[2]*internal]bool) []Change {
+	var changes []Change
+	pair := [2]*internal{a.Implementation, b.Implementation}
+	if a.IsEquiv(b) || visited[pair] {
+		return nil
+	}
+	visited[pair] = true
+	aMembers := a.Contents(opts.Methods)
+	bMembers := b.Contents(opts.Methods)
+	for name, aVal := range aMembers {
+		memberPath := append(append([]string(nil), path...), name)
+		bVal, ok := bMembers[name]
+		if !ok {
+			changes = append(changes, Change{Kind: Removed, Path: memberPath, Old: aVal})
+			continue
+		}
+		aObj, aIsObj := aVal.(Object)
+		bObj, bIsObj := bVal.(Object)
+		if opts.Recursive && aIsObj && bIsObj && aObj.Implementation != nil && bObj.Implementation != nil {
+			changes = append(changes, diffObjects(memberPath, aObj, bObj, opts, visited)...)
+			continue
+		}
+		if !valuesEqual(aVal, bVal, opts.Methods, nil, make(map[[2]*internal]bool)) {
+			changes = append(changes, Change{Kind: Modified, Path: memberPath, Old: aVal, New: bVal})
+		}
+	}
+	for name, bVal := range bMembers {
+		if _, ok := aMembers[name]; !ok {
+			memberPath := append(append([]string(nil), path...), name)
+			changes = append(changes, Change{Kind: Added, Path: memberPath, New: bVal})
+		}
+	}
+	return changes
+}
+
+// lessPath returns true if one member path sorts before another.
+func lessPath(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// A MergeStrategy specifies how MergeWith resolves a member that both
+// objects contain with different values.
+type MergeStrategy int
+
+// These are the strategies accepted by MergeWith.
+const (
+	MergeOurs     MergeStrategy = iota // Keep the object's own value
+	MergeTheirs                        // Replace the object's value with the source's
+	MergeConflict                      // Fail with a *ConflictError
+)
+
+// MergeWith is like Merge but resolves conflicts according to a
+// strategy.  A conflict is a member that the object itself contains
+// and whose value differs, as by Equal, from the source's.  With
+// MergeConflict, MergeWith copies nothing if there are any conflicts
+// and instead returns a *ConflictError listing them.  MergeWith also
+// returns any error that Set would return, in which case some members
+// may already have been copied.
+func (obj *Object) MergeWith(src Object, strategy MergeStrategy) error {
+	// Determine which members to copy.
+	members := src.Contents(true)
+	keys := make([]string, 0, len(members))
+	var conflicts []string
+	for key, value := range members {
+		existing, ok := obj.GetWithin(key, 0)
+		switch {
+		case !ok:
+			keys = append(keys, key)
+		case valuesEqual(existing, value, true, nil, make(map[[2]*internal]bool)):
+		case strategy == MergeTheirs:
+			keys = append(keys, key)
+		case strategy == MergeConflict:
+			conflicts = append(conflicts, key)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return &ConflictError{Members: conflicts}
+	}
+
+	// Copy the members.
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := obj.TrySet(key, members[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyPatch applies a list of changes, such as one returned by Diff,
+// to the object.  Added and Modified members are Set to their New
+// values, and Removed members are Unset.  A change whose Path names a
+// nested member is applied to the object found by following the
+// preceding names.  ApplyPatch stops at the first change that cannot
+// be applied and returns an error describing it; a *NotFoundError if
+// an intermediate member does not exist, a *TypeError if an
+// intermediate member is not an object, or any error that TrySet or
+// TryUnset would return.
+func (obj *Object) ApplyPatch(changes []Change) error {
+	for _, c := range changes {
+		if len(c.Path) == 0 {
+			return fmt.Errorf("Change %v has an empty path", c)
+		}
+		target := *obj
+		for _, name := range c.Path[:len(c.Path)-1] {
+			value, ok := target.GetOK(name)
+			if !ok {
+				return &NotFoundError{Member: name}
+			}
+			nested, ok := value.(Object)
+			if !ok {
+				return &TypeError{Member: name, Expected: objectType, Actual: reflect.TypeOf(value)}
+			}
+			target = nested
+		}
+		name := c.Path[len(c.Path)-1]
+		var err error
+		if c.Kind == Removed {
+			err = target.TryUnset(name)
+		} else {
+			err = target.TrySet(name, c.New)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
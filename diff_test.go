@@ -0,0 +1,103 @@
+// This file tests comparing objects member by member.
+
+package goop_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test reporting the differences between two objects.
+func TestDiff(t *testing.T) {
+	// Define two versions of a configuration.
+	oldServer := goop.New()
+	oldServer.Set("port", 80)
+	oldConfig := goop.New()
+	oldConfig.Set("name", "web")
+	oldConfig.Set("debug", true)
+	oldConfig.Set("server", oldServer)
+	newServer := goop.New()
+	newServer.Set("port", 8080)
+	newConfig := goop.New()
+	newConfig.Set("name", "web")
+	newConfig.Set("timeout", 30)
+	newConfig.Set("server", newServer)
+
+	// Ensure that a shallow comparison reports the nested object as
+	// modified.
+	changes := goop.Diff(oldConfig, newConfig)
+	expected := "[-debug: true ~server: {port: 80} -> {port: 8080} +timeout: 30]"
+	if result := fmt.Sprint(changes); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+
+	// Ensure that a recursive comparison reports the nested member.
+	changes = goop.DiffWith(oldConfig, newConfig, goop.DiffOptions{Recursive: true})
+	expected = "[-debug: true ~server.port: 80 -> 8080 +timeout: 30]"
+	if result := fmt.Sprint(changes); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+	if changes[1].Kind != goop.Modified || len(changes[1].Path) != 2 {
+		t.Fatalf("Expected %v at [server port] but saw %v at %v", goop.Modified, changes[1].Kind, changes[1].Path)
+	}
+
+	// Ensure that equal objects have no differences.
+	if changes := goop.Diff(oldConfig, oldConfig.Clone()); len(changes) != 0 {
+		t.Fatalf("Expected no changes but saw %v", changes)
+	}
+}
+
+// Test merging objects with each conflict strategy.
+func TestMergeWith(t *testing.T) {
+	defaults := goop.New()
+	defaults.Set("color", "blue")
+	defaults.Set("size", 10)
+	for _, strategy := range []goop.MergeStrategy{goop.MergeOurs, goop.MergeTheirs, goop.MergeConflict} {
+		obj := goop.New()
+		obj.Set("color", "red")
+		err := obj.MergeWith(defaults, strategy)
+		expectedColor, expectedSize := "red", 10
+		switch strategy {
+		case goop.MergeTheirs:
+			expectedColor = "blue"
+		case goop.MergeConflict:
+			if !errors.Is(err, goop.ErrConflict) {
+				t.Fatalf("Expected %v but saw %v", goop.ErrConflict, err)
+			}
+			expectedSize = 0
+		}
+		if color := obj.Get("color"); color != expectedColor {
+			t.Fatalf("Expected %q but saw %v", expectedColor, color)
+		}
+		if size, _ := obj.GetDefault("size", 0).(int); size != expectedSize {
+			t.Fatalf("Expected %d but saw %d", expectedSize, size)
+		}
+	}
+}
+
+// Test applying the changes reported by Diff.
+func TestApplyPatch(t *testing.T) {
+	a := goop.New()
+	a.Set("x", 1)
+	a.Set("y", 2)
+	a.Set("inner", goop.New())
+	b := a.DeepClone(false)
+	b.Unset("x")
+	b.Set("y", 3)
+	b.Set("z", 4)
+	inner := b.Get("inner").(goop.Object)
+	inner.Set("w", 5)
+	changes := goop.DiffWith(a, b, goop.DiffOptions{Recursive: true})
+	if err := a.ApplyPatch(changes); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if !a.Equal(b) {
+		t.Fatalf("Expected %v but saw %v", b, a)
+	}
+	bad := []goop.Change{{Kind: goop.Added, Path: []string{"y", "q"}, New: 0}}
+	if err := a.ApplyPatch(bad); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+}
@@ -0,0 +1,69 @@
+// This file implements events, which objects emit to notify handlers
+// registered by On.
+
+package goop
+
+import "fmt"
+import "reflect"
+
+// A handler represents a function registered with On.
+type handler struct {
+	function interface{} // Function to invoke when the event is emitted
+}
+
+// On registers a function to run whenever the named event is emitted
+// by the object or by any of its descendants.  The function is invoked
+// like a method on the emitting object: it receives the object as its
+// first argument if its first parameter is an Object, followed by the
+// arguments given to Emit.  On panics if the function is not a
+// function.  It returns a function that unregisters the handler.
+func (obj *Object) On(event string, function interface{}) (cancel func()) {
+	if reflect.TypeOf(function) == nil || reflect.TypeOf(function).Kind() != reflect.Func {
+		panic(fmt.Errorf("An event handler cannot be a %T", function))
+	}
+	h := &handler{function: function}
+	impl := obj.Implementation
+	impl.lock.Lock()
+	if impl.handlers == nil {
+		impl.handlers = make(map[string][]*handler)
+	}
+	impl.handlers[event] = appendItem(impl.handlers[event], h)
+	impl.lock.Unlock()
+	return func() {
+		impl.lock.Lock()
+		removeItem(impl.handlers, event, h)
+		impl.lock.Unlock()
+	}
+}
+
+// Off unregisters all of the object's handlers for the named event.
+// Handlers registered on the object's prototypes are unaffected.
+func (obj *Object) Off(event string) {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	delete(impl.handlers, event)
+	impl.lock.Unlock()
+}
+
+// Emit invokes each handler registered for the named event on the
+// object itself and then on each of its ancestors, in the order in
+// which Get searches them.  Handlers on the same object run in the
+// order in which they were registered.  Emit returns the number of
+// handlers invoked.  It panics with an *ArgumentError if a handler
+// does not accept the arguments and with a *PanicError if a handler
+// panics; handlers later in the order are then not invoked.
+func (obj *Object) Emit(event string, arguments ...interface{}) int {
+	var handlers []*handler
+	for _, ancestor := range obj.Ancestors(true) {
+		impl := ancestor.Implementation
+		impl.lock.RLock()
+		handlers = append(handlers, impl.handlers[event]...)
+		impl.lock.RUnlock()
+	}
+	for _, h := range handlers {
+		if _, err := obj.callMethod(event, h.function, arguments); err != nil {
+			panic(err)
+		}
+	}
+	return len(handlers)
+}
@@ -0,0 +1,65 @@
+// This file tests events.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test registering, emitting, and unregistering events.
+func TestEvents(t *testing.T) {
+	var log []string
+	proto := goop.New()
+	proto.On("collision", func(this goop.Object, other string) {
+		log = append(log, "proto: "+this.Get("name").(string)+" hit "+other)
+	})
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("name", "ship")
+	cancel := obj.On("collision", func(other string) {
+		log = append(log, "obj: "+other)
+	})
+	obj.On("collision", func(this goop.Object, other string) {
+		log = append(log, "obj again: "+other)
+	})
+	if n := obj.Emit("collision", "asteroid"); n != 3 {
+		t.Fatalf("Expected %d but saw %d", 3, n)
+	}
+	expected := []string{"obj: asteroid", "obj again: asteroid", "proto: ship hit asteroid"}
+	if len(log) != len(expected) {
+		t.Fatalf("Expected %v but saw %v", expected, log)
+	}
+	for i := range expected {
+		if log[i] != expected[i] {
+			t.Fatalf("Expected %v but saw %v", expected, log)
+		}
+	}
+
+	// Unregister handlers.
+	cancel()
+	if n := obj.Emit("collision", "comet"); n != 2 {
+		t.Fatalf("Expected %d but saw %d", 2, n)
+	}
+	obj.Off("collision")
+	if n := obj.Emit("collision", "moon"); n != 1 {
+		t.Fatalf("Expected %d but saw %d", 1, n)
+	}
+	if n := proto.Emit("landing"); n != 0 {
+		t.Fatalf("Expected %d but saw %d", 0, n)
+	}
+}
+
+// Test emitting an event with arguments a handler does not accept.
+func TestEmitBadArguments(t *testing.T) {
+	obj := goop.New()
+	obj.On("tick", func(n int) {})
+	defer func() {
+		r := recover()
+		if err, ok := r.(error); !ok || !errors.Is(err, goop.ErrBadArguments) {
+			t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, r)
+		}
+	}()
+	obj.Emit("tick", "soon")
+}
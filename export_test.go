@@ -0,0 +1,16 @@
+// This file exports internals for use by the tests.
+
+package goop
+
+// SetSuperUnchecked is like SetSuper but does not reject cycles, which
+// lets the tests construct a prototype chain that SetSuper would
+// refuse.
+func SetSuperUnchecked(obj Object, parents ...Object) {
+	prototypes := append([]Object(nil), parents...)
+	markPrototypes(prototypes)
+	impl := obj.Implementation
+	impl.lock.Lock()
+	impl.prototypes = prototypes
+	impl.prototypesChanged()
+	impl.lock.Unlock()
+}
@@ -0,0 +1,87 @@
+// This file implements direct invocation of methods with common
+// signatures, which avoids the cost of reflection.
+
+package goop
+
+// callFast invokes a method function directly, without reflection, if
+// both the function's signature and the types of its arguments are
+// among a small set of common cases.  It returns the function's
+// results and true in that case and nil and false otherwise, leaving
+// the caller to invoke the function via reflection.  Only arguments of
+// exactly a parameter's type (or any argument, for a parameter of type
+// interface{}) are handled, so callFast never accepts arguments that
+// reflection would reject or convert.
+func callFast(this Object, userFuncIface interface{}, arguments []interface{}) ([]interface{}, bool) {
+	switch len(arguments) {
+	case 0:
+		return callFast0(this, userFuncIface)
+	case 1:
+		return callFast1(this, userFuncIface, arguments[0])
+	}
+	return nil, false
+}
+
+// callFast0 implements callFast for methods invoked with no arguments.
+func callFast0(this Object, userFuncIface interface{}) ([]interface{}, bool) {
+	switch f := userFuncIface.(type) {
+	case func():
+		f()
+		return []interface{}{}, true
+	case func(Object):
+		f(this)
+		return []interface{}{}, true
+	case func(Object) interface{}:
+		return []interface{}{f(this)}, true
+	case func(Object) bool:
+		return []interface{}{f(this)}, true
+	case func(Object) int:
+		return []interface{}{f(this)}, true
+	case func(Object) float64:
+		return []interface{}{f(this)}, true
+	case func(Object) string:
+		return []interface{}{f(this)}, true
+	case func(Object) error:
+		return []interface{}{f(this)}, true
+	}
+	return nil, false
+}
+
+// callFast1 implements callFast for methods invoked with a single
+// argument.
+func callFast1(this Object, userFuncIface interface{}, arg interface{}) ([]interface{}, bool) {
+	switch f := userFuncIface.(type) {
+	case func(Object, interface{}):
+		f(this, arg)
+		return []interface{}{}, true
+	case func(Object, interface{}) interface{}:
+		return []interface{}{f(this, arg)}, true
+	case func(Object, int):
+		if x, ok := arg.(int); ok {
+			f(this, x)
+			return []interface{}{}, true
+		}
+	case func(Object, int) int:
+		if x, ok := arg.(int); ok {
+			return []interface{}{f(this, x)}, true
+		}
+	case func(Object, float64):
+		if x, ok := arg.(float64); ok {
+			f(this, x)
+			return []interface{}{}, true
+		}
+	case func(Object, float64) float64:
+		if x, ok := arg.(float64); ok {
+			return []interface{}{f(this, x)}, true
+		}
+	case func(Object, string):
+		if s, ok := arg.(string); ok {
+			f(this, s)
+			return []interface{}{}, true
+		}
+	case func(Object, string) string:
+		if s, ok := arg.(string); ok {
+			return []interface{}{f(this, s)}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,62 @@
+// This file tests direct invocation of methods with common signatures.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that methods with common signatures return the expected
+// results.
+func TestFastCall(t *testing.T) {
+	obj := goop.New()
+	obj.Set("value", 21)
+	obj.Set("nothing", func() {})
+	obj.Set("get", func(this goop.Object) int { return this.Get("value").(int) })
+	obj.Set("double", func(this goop.Object, x int) int { return 2 * x })
+	obj.Set("half", func(this goop.Object, x float64) float64 { return x / 2 })
+	obj.Set("greet", func(this goop.Object, s string) string { return "Hello, " + s })
+	obj.Set("identity", func(this goop.Object, x interface{}) interface{} { return x })
+	obj.Set("fail", func(this goop.Object) error { return nil })
+	if results := obj.Call("nothing"); results == nil || len(results) != 0 {
+		t.Fatalf("Expected %d results but saw %v", 0, results)
+	}
+	if result := obj.Call("get")[0]; result != 21 {
+		t.Fatalf("Expected %d but saw %v", 21, result)
+	}
+	if result := obj.Call("double", 21)[0]; result != 42 {
+		t.Fatalf("Expected %d but saw %v", 42, result)
+	}
+	if result := obj.Call("half", 3.0)[0]; result != 1.5 {
+		t.Fatalf("Expected %.1f but saw %v", 1.5, result)
+	}
+	if result := obj.Call("greet", "Goop")[0]; result != "Hello, Goop" {
+		t.Fatalf("Expected %q but saw %v", "Hello, Goop", result)
+	}
+	if result := obj.Call("identity", nil)[0]; result != nil {
+		t.Fatalf("Expected %v but saw %v", nil, result)
+	}
+	if result := obj.Call("fail")[0]; result != nil {
+		t.Fatalf("Expected %v but saw %v", nil, result)
+	}
+}
+
+// Test that arguments not of exactly a parameter's type are still
+// converted or rejected as they would be via reflection.
+func TestFastCallFallback(t *testing.T) {
+	type celsius float64
+	obj := goop.New()
+	obj.Set("half", func(this goop.Object, x float64) float64 { return x / 2 })
+	obj.Set("halfCelsius", func(this goop.Object, x celsius) celsius { return x / 2 })
+	if _, err := obj.CallErr("half", "three"); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+	if _, err := obj.CallErr("half", 1.0, 2.0); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+	if result := obj.Call("halfCelsius", celsius(3))[0]; result != celsius(1.5) {
+		t.Fatalf("Expected %.1f but saw %v", 1.5, result)
+	}
+}
@@ -0,0 +1,71 @@
+// This file implements finalization, which lets objects release
+// resources when they are no longer needed.
+
+package goop
+
+import "runtime"
+
+// Finalize names the method that Destroy invokes to release an
+// object's resources.  The method is typically defined as
+//
+//	func(this goop.Object)
+//
+// or, to report a failure, as
+//
+//	func(this goop.Object) error
+const Finalize = "__finalize__"
+
+// Destroy invokes the object's Finalize method, if any, and returns
+// the error it returns, if any.  Destroy invokes the method at most
+// once per object, however many times Destroy is called and whether or
+// not a finalizer registered by EnableFinalizer runs later.  Destroy
+// returns the same errors as CallErr apart from ErrNotFound.
+func (obj *Object) Destroy() error {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	if impl.destroyed {
+		impl.lock.Unlock()
+		return nil
+	}
+	impl.destroyed = true
+	impl.lock.Unlock()
+	impl.setFinalizer(nil)
+	if !obj.HasMember(Finalize) {
+		return nil
+	}
+	results, err := obj.CallErr(Finalize)
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		if err, ok := results[len(results)-1].(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableFinalizer arranges for the garbage collector to invoke Destroy
+// on the object once the object becomes unreachable, unless Destroy
+// has already been called.  As with runtime.SetFinalizer, there is no
+// guarantee that this will happen before the program exits, and an
+// object that refers to itself (directly or through other objects'
+// members) may never be finalized.  Call Destroy explicitly for
+// deterministic cleanup.  EnableFinalizer has no effect on an object
+// allocated from an Arena.
+func (obj *Object) EnableFinalizer() {
+	obj.Implementation.setFinalizer(func(impl *internal) {
+		finalized := Object{Implementation: impl}
+		finalized.Destroy()
+	})
+}
+
+// setFinalizer is like runtime.SetFinalizer but does nothing for an
+// object allocated from an Arena, whose storage lies in the middle of
+// a slab rather than at the beginning of an allocated block.
+func (impl *internal) setFinalizer(finalizer interface{}) {
+	if impl.inArena {
+		return
+	}
+	runtime.SetFinalizer(impl, finalizer)
+}
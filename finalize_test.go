@@ -0,0 +1,59 @@
+// This file tests releasing objects' resources.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Test explicitly destroying an object.
+func TestDestroy(t *testing.T) {
+	// Ensure that an inherited finalizer runs exactly once.
+	errClose := errors.New("Close failed")
+	closed := 0
+	proto := goop.New()
+	proto.Set(goop.Finalize, func(this goop.Object) error {
+		closed++
+		return errClose
+	})
+	obj := goop.New()
+	obj.SetSuper(proto)
+	if err := obj.Destroy(); err != errClose {
+		t.Fatalf("Expected %v but saw %v", errClose, err)
+	}
+	if err := obj.Destroy(); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if closed != 1 {
+		t.Fatalf("Expected %d but saw %d", 1, closed)
+	}
+
+	// Ensure that an object without a finalizer can be destroyed.
+	plain := goop.New()
+	if err := plain.Destroy(); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+}
+
+// Test finalizing an unreachable object.
+func TestEnableFinalizer(t *testing.T) {
+	done := make(chan bool, 1)
+	func() {
+		obj := goop.New()
+		obj.Set(goop.Finalize, func(this goop.Object) { done <- true })
+		obj.EnableFinalizer()
+	}()
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		select {
+		case <-done:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("Expected the object to be finalized")
+}
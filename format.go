@@ -0,0 +1,218 @@
+// This file provides support for rendering objects as text.
+
+package goop
+
+import "fmt"
+import "io"
+import "reflect"
+import "sort"
+import "strconv"
+import "strings"
+
+// ToString names the member that String invokes, if present, to
+// render an object as a string.  The member is typically defined as
+//
+//	func(this goop.Object) string
+const ToString = "toString"
+
+// String implements fmt.Stringer.  If the object has a ToString method
+// (directly or via a prototype), String invokes it and returns its
+// result.  Otherwise, String returns a concise rendering of the
+// object's data members, as listed by Contents(false), in the form
+// {name: value, ...} with names in lexical order.  Members whose values
+// are themselves objects are rendered recursively, with an object that
+// contains itself rendered as {...}.
+func (obj Object) String() string {
+	if obj.Implementation == nil {
+		return "<nil>"
+	}
+	var b strings.Builder
+	writeObject(&b, obj, make(map[*internal]bool))
+	return b.String()
+}
+
+// writeObject writes the String representation of an object.  Objects
+// in the set of those already being written are rendered as {...}.
+func writeObject(b *strings.Builder, obj Object, visiting map[*internal]bool) {
+	if toString, ok := obj.GetOK(ToString); ok && reflect.ValueOf(toString).Kind() == reflect.Func {
+		results := obj.Call(ToString)
+		if len(results) > 0 {
+			fmt.Fprint(b, results[0])
+			return
+		}
+	}
+	if visiting[obj.Implementation] {
+		b.WriteString("{...}")
+		return
+	}
+	visiting[obj.Implementation] = true
+	defer delete(visiting, obj.Implementation)
+	contents := obj.Contents(false)
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(": ")
+		if sub, ok := contents[name].(Object); ok && sub.Implementation != nil {
+			writeObject(b, sub, visiting)
+		} else {
+			fmt.Fprint(b, contents[name])
+		}
+	}
+	b.WriteByte('}')
+}
+
+// InspectOptions control the output of Inspect.
+type InspectOptions struct {
+	Methods bool   // true to list methods and their signatures as well as data members
+	Depth   int    // Maximum number of prototype levels to show (0 for unlimited, negative for none)
+	Indent  string // String to indent each prototype level (default: two spaces)
+}
+
+// Inspect writes to w a human-readable description of an object
+// intended for debugging.  It lists the object's own members in
+// lexical order, one per line, followed by each of its prototypes,
+// indented, which are described the same way.  A prototype reachable
+// via more than one path is described only the first time and is
+// thereafter marked as already shown.  Inspect returns the first error
+// encountered while writing.
+func (obj *Object) Inspect(w io.Writer, opts InspectOptions) error {
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+	var b strings.Builder
+	inspectObject(&b, *obj, opts, "", 1, make(map[*internal]int))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// inspectObject writes the Inspect output for one object at a given
+// indentation and prototype level.  shown maps each object already
+// written to a number that identifies it in the output.
+func inspectObject(b *strings.Builder, obj Object, opts InspectOptions, indent string, level int, shown map[*internal]int) {
+	impl := obj.Implementation
+	if id, ok := shown[impl]; ok {
+		fmt.Fprintf(b, "%sobject #%d (already shown)\n", indent, id)
+		return
+	}
+	shown[impl] = len(shown) + 1
+	fmt.Fprintf(b, "%sobject #%d\n", indent, shown[impl])
+
+	// Write the object's own members.
+	impl.lock.RLock()
+	local := impl.symbolTable.toMap()
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	names := make([]string, 0, len(local))
+	for name := range local {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := memberValue(obj, local[name])
+		switch {
+		case reflect.ValueOf(value).Kind() != reflect.Func:
+			fmt.Fprintf(b, "%s%s%s: %s\n", indent, opts.Indent, name, inspectValue(value))
+		case opts.Methods:
+			fmt.Fprintf(b, "%s%s%s: %s\n", indent, opts.Indent, name, methodSignature(value))
+		}
+	}
+
+	// Write the object's prototypes.
+	if opts.Depth < 0 || (opts.Depth > 0 && level > opts.Depth) {
+		if len(prototypes) > 0 {
+			fmt.Fprintf(b, "%s%s(prototypes not shown: %d)\n", indent, opts.Indent, len(prototypes))
+		}
+		return
+	}
+	for _, proto := range prototypes {
+		inspectObject(b, proto, opts, indent+opts.Indent, level+1, shown)
+	}
+}
+
+// inspectValue renders a data member's value for Inspect.  Strings
+// are quoted, and objects are rendered by String.
+func inspectValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case Object:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// methodSignature renders a method's type or, for a MetaFunction that
+// combines several functions, each of their types.
+func methodSignature(method interface{}) string {
+	if mf, ok := method.(MetaFunction); ok && mf.dispatcher() != nil {
+		return strings.Join(mf.Signatures(), " | ")
+	}
+	return reflect.TypeOf(method).String()
+}
+
+// DOTOptions control the output of ExportDOTWith.
+type DOTOptions struct {
+	Members []string // Names of members to include in each node's label
+}
+
+// ExportDOT writes to w a Graphviz DOT graph of a set of objects and
+// all of their ancestors.  Each object is drawn once, as a node
+// labeled with a number, and each prototype relationship is drawn as an
+// edge from an object to its prototype.  ExportDOT returns the first
+// error encountered while writing.
+func ExportDOT(w io.Writer, roots ...Object) error {
+	return ExportDOTWith(w, DOTOptions{}, roots...)
+}
+
+// ExportDOTWith is like ExportDOT but additionally labels each node
+// with the values of the selected members that the object itself
+// contains.
+func ExportDOTWith(w io.Writer, opts DOTOptions, roots ...Object) error {
+	// Number every object reachable from the roots.
+	ids := make(map[*internal]int)
+	var objs []Object
+	for _, root := range roots {
+		for _, obj := range root.Ancestors(true) {
+			if _, ok := ids[obj.Implementation]; !ok {
+				objs = append(objs, obj)
+				ids[obj.Implementation] = len(objs)
+			}
+		}
+	}
+
+	// Write a node for each object and an edge for each prototype.
+	var b strings.Builder
+	b.WriteString("digraph goop {\n")
+	for _, obj := range objs {
+		id := ids[obj.Implementation]
+		label := fmt.Sprintf("#%d", id)
+		for _, name := range opts.Members {
+			if value, ok := obj.GetWithin(name, 0); ok {
+				label += fmt.Sprintf("\n%s: %s", name, inspectValue(value))
+			}
+		}
+		fmt.Fprintf(&b, "  n%d [label=\"%s\"];\n", id, dotEscape(label))
+	}
+	for _, obj := range objs {
+		for _, proto := range obj.Super() {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", ids[obj.Implementation], ids[proto.Implementation])
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotEscape escapes a string for use within a quoted DOT identifier.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
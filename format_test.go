@@ -0,0 +1,92 @@
+// This file tests rendering objects as text.
+
+package goop_test
+
+import (
+	"fmt"
+	"github.com/lanl/goop"
+	"strings"
+	"testing"
+)
+
+// Test rendering objects with and without a toString method.
+func TestString(t *testing.T) {
+	// Ensure that data members are listed in order, that methods
+	// are omitted, and that cycles are tolerated.
+	point := goop.New()
+	point.Set("y", 2)
+	point.Set("x", 1)
+	point.Set("norm", func(this goop.Object) int { return 0 })
+	point.Set("self", point)
+	if result := fmt.Sprint(point); result != "{self: {...}, x: 1, y: 2}" {
+		t.Fatalf("Expected %q but saw %q", "{self: {...}, x: 1, y: 2}", result)
+	}
+
+	// Ensure that a toString method takes precedence.
+	point.Set(goop.ToString, func(this goop.Object) string {
+		return fmt.Sprintf("(%d, %d)", this.Get("x"), this.Get("y"))
+	})
+	if result := fmt.Sprintf("%v", point); result != "(1, 2)" {
+		t.Fatalf("Expected %q but saw %q", "(1, 2)", result)
+	}
+}
+
+// Test describing an object and its prototypes.
+func TestInspect(t *testing.T) {
+	// Define a two-level prototype hierarchy.
+	base := goop.New()
+	base.Set("kind", "shape")
+	base.Set("area", func(this goop.Object) float64 { return 0 })
+	square := goop.New()
+	square.SetSuper(base)
+	square.Set("side", 3)
+
+	// Ensure that members and prototypes are described.
+	var b strings.Builder
+	if err := square.Inspect(&b, goop.InspectOptions{Methods: true}); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	expected := `object #1
+  side: 3
+  object #2
+    area: func(goop.Object) float64
+    kind: "shape"
+`
+	if result := b.String(); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+
+	// Ensure that methods and deeper prototypes can be omitted.
+	b.Reset()
+	square.Inspect(&b, goop.InspectOptions{Depth: -1})
+	expected = "object #1\n  side: 3\n  (prototypes not shown: 1)\n"
+	if result := b.String(); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+}
+
+// Test exporting a prototype graph in DOT format.
+func TestExportDOT(t *testing.T) {
+	animal := goop.New()
+	animal.Set("name", "animal")
+	dog := goop.New()
+	dog.SetSuper(animal)
+	dog.Set("name", "dog")
+	cat := goop.New()
+	cat.SetSuper(animal)
+	var b strings.Builder
+	if err := goop.ExportDOTWith(&b, goop.DOTOptions{Members: []string{"name"}}, dog, cat); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	expected := `digraph goop {
+  n1 [label="#1\nname: \"dog\""];
+  n2 [label="#2\nname: \"animal\""];
+  n3 [label="#3"];
+  n1 -> n2;
+  n3 -> n2;
+}
+`
+	if result := b.String(); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+}
@@ -0,0 +1,145 @@
+// This file implements finite state machines, which let an object's
+// methods depend on the state it is in rather than on hand-written
+// switch statements.
+
+package goop
+
+import "errors"
+import "fmt"
+
+// StateMember names the member that holds an object's current state.
+// Because the state is an ordinary member, an object inherits its
+// initial state from the prototype that defines its states, and Fire
+// stores each new state in the object itself.
+const StateMember = "state"
+
+// EnterPrefix and ExitPrefix begin the names of the methods that Fire
+// invokes when an object enters and exits a state.  For example, an
+// object entering the "open" state invokes its "enter:open" method, if
+// any, and an object leaving that state invokes its "exit:open" method.
+const (
+	EnterPrefix = "enter:"
+	ExitPrefix  = "exit:"
+)
+
+// machineMember names the hidden member that holds the transitions
+// defined by DefineStates.
+const machineMember = "__states__"
+
+// ErrNoTransition is returned by an attempt to fire an event for which
+// no transition applies in an object's current state.
+var ErrNoTransition = errors.New("No transition for event")
+
+// A Transition describes a change of state in response to an event.
+type Transition struct {
+	Event string // Name of the event that triggers the transition
+	From  string // State in which the transition applies, or "" for any state
+	To    string // State to which the transition leads
+	Guard string // Name of a method that must return true for the transition to apply, or "" for none
+}
+
+// A machine represents the transitions defined by DefineStates.
+type machine struct {
+	transitions []Transition // Transitions in the order they were given
+}
+
+// DefineStates makes the object a finite state machine that starts in
+// a given state and moves between states as events are passed to Fire.
+// The object's descendants share its transitions and initial state but
+// each keep their own current state.  DefineStates replaces any
+// transitions the object previously defined and resets its state (see
+// StateMember) to the initial state.  It panics with ErrFrozen if the
+// object is frozen.
+func (obj *Object) DefineStates(initial string, transitions ...Transition) {
+	m := &machine{transitions: append([]Transition(nil), transitions...)}
+	obj.DefineProperty(machineMember, Descriptor{
+		Get: func(Object) interface{} { return m },
+	})
+	obj.Set(StateMember, initial)
+}
+
+// State returns the object's current state or "" if the object has no
+// state.
+func (obj *Object) State() string {
+	state, _ := obj.Get(StateMember).(string)
+	return state
+}
+
+// Fire moves the object to a new state in response to an event.  Fire
+// considers, in the order given to DefineStates, each transition for
+// the event whose From state is the object's current state or "".  It
+// takes the first such transition whose Guard method, if any, returns
+// true when passed the given arguments.  Fire then invokes the current
+// state's exit method, if any, sets the object's state, and invokes
+// the new state's entry method, if any, passing each the same
+// arguments.  Guards and entry and exit methods are located like any
+// other method, so a prototype can supply them for all of its
+// descendants.  Fire returns an error wrapping ErrNoTransition if no
+// transition applies, in which case the object's state is unchanged.
+// It returns the same errors as CallErr if a method cannot be invoked,
+// a *TypeError if a Guard returns something other than a bool, and
+// the error returned by an entry or exit method whose final result is
+// a non-nil error.  An error from an exit method leaves the object in
+// its current state.  Fire does not serialize concurrent transitions
+// on the same object; use an Actor for that.
+func (obj *Object) Fire(event string, arguments ...interface{}) error {
+	m, _ := obj.Get(machineMember).(*machine)
+	if m == nil {
+		return fmt.Errorf("%w %q (no states are defined)", ErrNoTransition, event)
+	}
+	from := obj.State()
+	t, err := obj.findTransition(m, event, from, arguments)
+	if err != nil {
+		return err
+	}
+	if err := obj.callStateMethod(ExitPrefix+from, arguments); err != nil {
+		return err
+	}
+	if err := obj.TrySet(StateMember, t.To); err != nil {
+		return err
+	}
+	return obj.callStateMethod(EnterPrefix+t.To, arguments)
+}
+
+// findTransition returns the first transition for an event that
+// applies in a given state.
+func (obj *Object) findTransition(m *machine, event, state string, arguments []interface{}) (Transition, error) {
+	for _, t := range m.transitions {
+		if t.Event != event || (t.From != "" && t.From != state) {
+			continue
+		}
+		if t.Guard == "" {
+			return t, nil
+		}
+		result, err := obj.Call1(t.Guard, arguments...)
+		if err != nil {
+			return Transition{}, err
+		}
+		pass, err := convertTo[bool](t.Guard, result)
+		if err != nil {
+			return Transition{}, err
+		}
+		if pass {
+			return t, nil
+		}
+	}
+	return Transition{}, fmt.Errorf("%w %q in state %q", ErrNoTransition, event, state)
+}
+
+// callStateMethod invokes an entry or exit method, if the object has
+// one, and returns the error it reports, if any.
+func (obj *Object) callStateMethod(methodName string, arguments []interface{}) error {
+	if !obj.HasMember(methodName) {
+		return nil
+	}
+	results, err := obj.CallErr(methodName, arguments...)
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		if err, ok := results[len(results)-1].(error); ok {
+			return err
+		}
+	}
+	return nil
+}
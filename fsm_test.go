@@ -0,0 +1,90 @@
+// This file tests finite state machines.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test firing events that move an object between states.
+func TestStates(t *testing.T) {
+	var log []string
+	door := goop.New()
+	door.DefineStates("closed",
+		goop.Transition{Event: "open", From: "closed", To: "open"},
+		goop.Transition{Event: "close", From: "open", To: "closed"},
+		goop.Transition{Event: "lock", From: "closed", To: "locked", Guard: "hasKey"},
+		goop.Transition{Event: "unlock", From: "locked", To: "closed", Guard: "hasKey"},
+	)
+	door.Set("hasKey", func(this goop.Object, key string) bool { return key == "brass" })
+	door.Set("exit:closed", func(this goop.Object, args ...interface{}) { log = append(log, "exit closed") })
+	door.Set("enter:open", func(this goop.Object, args ...interface{}) { log = append(log, "enter open") })
+	front := goop.New()
+	front.SetSuper(door)
+	if state := front.State(); state != "closed" {
+		t.Fatalf("Expected %q but saw %q", "closed", state)
+	}
+	if err := front.Fire("open"); err != nil {
+		t.Fatal(err)
+	}
+	if state := front.State(); state != "open" {
+		t.Fatalf("Expected %q but saw %q", "open", state)
+	}
+	if state := door.State(); state != "closed" {
+		t.Fatalf("Expected %q but saw %q", "closed", state)
+	}
+	expected := []string{"exit closed", "enter open"}
+	if len(log) != len(expected) || log[0] != expected[0] || log[1] != expected[1] {
+		t.Fatalf("Expected %v but saw %v", expected, log)
+	}
+
+	// Fire an event that doesn't apply in the current state.
+	if err := front.Fire("open"); !errors.Is(err, goop.ErrNoTransition) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoTransition, err)
+	}
+
+	// Fire events whose transitions have guards.
+	if err := front.Fire("close"); err != nil {
+		t.Fatal(err)
+	}
+	if err := front.Fire("lock", "iron"); !errors.Is(err, goop.ErrNoTransition) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoTransition, err)
+	}
+	if err := front.Fire("lock", "brass"); err != nil {
+		t.Fatal(err)
+	}
+	if state := front.State(); state != "locked" {
+		t.Fatalf("Expected %q but saw %q", "locked", state)
+	}
+}
+
+// Test that errors from guards and entry and exit methods stop a
+// transition.
+func TestStatesErrors(t *testing.T) {
+	errStuck := errors.New("stuck")
+	obj := goop.New()
+	obj.DefineStates("idle",
+		goop.Transition{Event: "start", To: "running"},
+		goop.Transition{Event: "check", To: "idle", Guard: "ready"},
+	)
+	obj.Set("exit:idle", func(this goop.Object) error { return errStuck })
+	if err := obj.Fire("start"); err != errStuck {
+		t.Fatalf("Expected %v but saw %v", errStuck, err)
+	}
+	if state := obj.State(); state != "idle" {
+		t.Fatalf("Expected %q but saw %q", "idle", state)
+	}
+	if err := obj.Fire("check"); !errors.Is(err, goop.ErrNoSuchMethod) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoSuchMethod, err)
+	}
+	obj.Set("ready", func(this goop.Object) int { return 1 })
+	if err := obj.Fire("check"); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+	plain := goop.New()
+	if err := plain.Fire("start"); !errors.Is(err, goop.ErrNoTransition) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoTransition, err)
+	}
+}
@@ -0,0 +1,63 @@
+// This file implements asynchronous method invocation.
+
+package goop
+
+import "context"
+
+// A Future represents the eventual results of a method invoked
+// asynchronously by Go.  A Future is safe for concurrent use by
+// multiple goroutines.
+type Future struct {
+	done    chan struct{} // Channel closed when the method completes
+	results []interface{} // Method's results, valid once done is closed
+	err     error         // Error reported by CallErr, valid once done is closed
+}
+
+// newFuture returns a Future that has not yet completed.
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// complete records a Future's results and wakes its waiters.
+func (f *Future) complete(results []interface{}, err error) {
+	f.results = results
+	f.err = err
+	close(f.done)
+}
+
+// Go invokes a method asynchronously in a new goroutine and returns a
+// Future for its results.  The method is invoked as by CallErr, so a
+// missing method, unacceptable arguments, or a panic within the method
+// is reported as the Future's error rather than crashing the program.
+func (obj *Object) Go(methodName string, arguments ...interface{}) *Future {
+	f := newFuture()
+	this := *obj
+	go func() {
+		f.complete(this.CallErr(methodName, arguments...))
+	}()
+	return f
+}
+
+// Done returns a channel that is closed when the method completes.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the method completes and returns its results and
+// the error, if any, reported as by CallErr.
+func (f *Future) Wait() ([]interface{}, error) {
+	<-f.done
+	return f.results, f.err
+}
+
+// WaitContext is like Wait but stops waiting if ctx is canceled or
+// past its deadline before the method completes, in which case it
+// returns ctx.Err().  The method itself continues to run.
+func (f *Future) WaitContext(ctx context.Context) ([]interface{}, error) {
+	select {
+	case <-f.done:
+		return f.results, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+// This file tests asynchronous method invocation.
+
+package goop_test
+
+import (
+	"context"
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test waiting for asynchronous calls to complete.
+func TestGo(t *testing.T) {
+	obj := goop.New()
+	obj.Set("square", func(this goop.Object, x int) int { return x * x })
+	futures := make([]*goop.Future, 10)
+	for i := range futures {
+		futures[i] = obj.Go("square", i)
+	}
+	for i, f := range futures {
+		results, err := f.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0] != i*i {
+			t.Fatalf("Expected %d but saw %v", i*i, results[0])
+		}
+		select {
+		case <-f.Done():
+		default:
+			t.Fatalf("Expected Done to be closed after Wait")
+		}
+	}
+}
+
+// Test that asynchronous calls report errors and panics.
+func TestGoErrors(t *testing.T) {
+	obj := goop.New()
+	obj.Set("fail", func(this goop.Object) { panic("oops") })
+	if _, err := obj.Go("fail").Wait(); !errors.As(err, new(*goop.PanicError)) {
+		t.Fatalf("Expected a *PanicError but saw %v", err)
+	}
+	if _, err := obj.Go("missing").Wait(); !errors.Is(err, goop.ErrNoSuchMethod) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoSuchMethod, err)
+	}
+}
+
+// Test abandoning a wait when a context is canceled.
+func TestFutureWaitContext(t *testing.T) {
+	release := make(chan struct{})
+	obj := goop.New()
+	obj.Set("block", func(this goop.Object) int {
+		<-release
+		return 1
+	})
+	f := obj.Go("block")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.WaitContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v but saw %v", context.Canceled, err)
+	}
+	close(release)
+	if results, err := f.WaitContext(context.Background()); err != nil || results[0] != 1 {
+		t.Fatalf("Expected %d but saw %v (%v)", 1, results, err)
+	}
+}
@@ -0,0 +1,196 @@
+// This file provides support for converting objects to and from gobs.
+
+package goop
+
+import "bytes"
+import "encoding/gob"
+import "fmt"
+import "reflect"
+
+// Register Object so objects can be gob-encoded as interface values,
+// in particular as members of other objects.  Register gobRef so
+// Graph can encode references to objects as member values.
+func init() {
+	gob.Register(Object{})
+	gob.Register(gobRef{})
+}
+
+// GobEncode encodes an object's data members as a gob.  As with
+// MarshalJSON, the members are those returned by Contents(false), so
+// method functions are omitted.  The dynamic type of each member value
+// must be one that gob can encode as an interface value (i.e., a basic
+// type or a type passed to gob.Register).  Members whose values are
+// themselves objects are encoded recursively.
+func (obj Object) GobEncode() ([]byte, error) {
+	var members map[string]interface{}
+	if obj.Implementation != nil {
+		members = obj.Contents(false)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(members); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the object with a new object whose members are
+// decoded from a gob produced by GobEncode.  The new object has no
+// prototypes and no method functions.
+func (obj *Object) GobDecode(data []byte) error {
+	var members map[string]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&members); err != nil {
+		return err
+	}
+	newObj := newObject()
+	for key, val := range members {
+		newObj.Implementation.symbolTable.put(key, val)
+	}
+	*obj = newObj
+	return nil
+}
+
+// A Graph is a list of objects that are gob-encoded together with
+// their prototypes and with any objects they contain as members.
+// Unlike with GobEncode, each object's own members and prototypes are
+// encoded separately rather than flattened, and an object reachable
+// via multiple paths (e.g., a prototype shared by two objects) is
+// encoded only once.  Hence, after decoding, two objects that shared
+// a parent still share a parent.  Method functions are omitted, and
+// computed members are encoded as their current values.  Objects
+// nested within other data structures (e.g., slices of objects) are
+// encoded with GobEncode and therefore not shared.
+type Graph []Object
+
+// A gobRef refers to an object in a gobGraph by its index.
+type gobRef struct {
+	ID int
+}
+
+// A gobNode is the gob representation of a single object in a Graph.
+type gobNode struct {
+	Members    map[string]interface{} // Object's own data members
+	Prototypes []int                  // Index of each of the object's prototypes
+}
+
+// A gobGraph is the gob representation of a Graph.
+type gobGraph struct {
+	Roots []int     // Index of each object in the Graph (-1 for a nil object)
+	Nodes []gobNode // All objects reachable from the Graph's objects
+}
+
+// GobEncode encodes a graph of objects as a gob.
+func (g Graph) GobEncode() ([]byte, error) {
+	ids := make(map[*internal]int)
+	var gg gobGraph
+	var visit func(obj Object) int
+	visit = func(obj Object) int {
+		if id, seen := ids[obj.Implementation]; seen {
+			return id
+		}
+		id := len(gg.Nodes)
+		ids[obj.Implementation] = id
+		gg.Nodes = append(gg.Nodes, gobNode{})
+
+		// Snapshot the object's own members and prototypes.
+		impl := obj.Implementation
+		impl.lock.RLock()
+		local := impl.symbolTable.toMap()
+		prototypes := impl.prototypes
+		impl.lock.RUnlock()
+
+		// Encode data members, replacing objects with references.
+		node := gobNode{
+			Members:    make(map[string]interface{}, len(local)),
+			Prototypes: make([]int, len(prototypes)),
+		}
+		for key, val := range local {
+			val = memberValue(obj, val)
+			if reflect.ValueOf(val).Kind() == reflect.Func {
+				continue
+			}
+			if nested, ok := val.(Object); ok && nested.Implementation != nil {
+				val = gobRef{ID: visit(nested)}
+			}
+			node.Members[key] = val
+		}
+		for i, proto := range prototypes {
+			node.Prototypes[i] = visit(proto)
+		}
+		gg.Nodes[id] = node
+		return id
+	}
+	gg.Roots = make([]int, len(g))
+	for i, obj := range g {
+		if obj.Implementation == nil {
+			gg.Roots[i] = -1
+		} else {
+			gg.Roots[i] = visit(obj)
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces a graph with a graph of new objects decoded from
+// a gob produced by Graph.GobEncode.
+func (g *Graph) GobDecode(data []byte) error {
+	// Decode the graph and allocate all of its objects.
+	var gg gobGraph
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&gg); err != nil {
+		return err
+	}
+	objs := make([]Object, len(gg.Nodes))
+	for i := range objs {
+		objs[i] = newObject()
+	}
+	lookup := func(id int) (Object, error) {
+		if id < 0 || id >= len(objs) {
+			return Object{}, fmt.Errorf("Invalid object reference %d in gob", id)
+		}
+		return objs[id], nil
+	}
+
+	// Populate each object's members and prototypes.
+	for i, node := range gg.Nodes {
+		symbolTable := &objs[i].Implementation.symbolTable
+		for key, val := range node.Members {
+			if ref, ok := val.(gobRef); ok {
+				nested, err := lookup(ref.ID)
+				if err != nil {
+					return err
+				}
+				val = nested
+			}
+			symbolTable.put(key, val)
+		}
+		prototypes := make([]interface{}, len(node.Prototypes))
+		for j, id := range node.Prototypes {
+			proto, err := lookup(id)
+			if err != nil {
+				return err
+			}
+			prototypes[j] = proto
+		}
+		if err := objs[i].TrySetSuper(prototypes...); err != nil {
+			return err
+		}
+	}
+
+	// Replace the graph's objects with the decoded roots.
+	result := make(Graph, len(gg.Roots))
+	for i, id := range gg.Roots {
+		if id == -1 {
+			continue
+		}
+		root, err := lookup(id)
+		if err != nil {
+			return err
+		}
+		result[i] = root
+	}
+	*g = result
+	return nil
+}
@@ -0,0 +1,86 @@
+// This file tests converting objects to and from gobs.
+
+package goop_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test a round trip of an object through a gob.
+func TestGobRoundTrip(t *testing.T) {
+	// Encode a point with an inherited member, a nested object,
+	// and a method.
+	parent := goop.New()
+	parent.Set("label", "origin")
+	point := goop.New()
+	point.SetSuper(parent)
+	point.Set("x", 3)
+	point.Set("scale", 0.5)
+	point.Set("length", func(this goop.Object) int { return 5 })
+	color := goop.New()
+	color.Set("name", "red")
+	point.Set("color", color)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(point); err != nil {
+		t.Fatal(err)
+	}
+
+	// Decode the point and ensure that it has the same data members.
+	var decoded goop.Object
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(point) {
+		t.Fatalf("Expected %v but saw %v", point.Contents(false), decoded.Contents(false))
+	}
+	if result := decoded.Get("label").(string); result != "origin" {
+		t.Fatalf("Expected %q but saw %v", "origin", result)
+	}
+	if decoded.HasMember("length") {
+		t.Fatalf("Expected method %q not to be encoded", "length")
+	}
+}
+
+// Test a round trip of a graph of objects through a gob.
+func TestGobGraph(t *testing.T) {
+	// Encode two children of a common parent, one of which refers
+	// to the other.
+	parent := goop.New()
+	parent.Set("kind", "shape")
+	child1 := goop.New()
+	child1.SetSuper(parent)
+	child1.Set("sides", 3)
+	child2 := goop.New()
+	child2.SetSuper(parent)
+	child2.Set("sides", 4)
+	child2.Set("sibling", child1)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(goop.Graph{child1, child2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure that the decoded children still share a parent.
+	var decoded goop.Graph
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("Expected %d objects but saw %d", 2, len(decoded))
+	}
+	parent1 := decoded[0].Super()
+	parent2 := decoded[1].Super()
+	if len(parent1) != 1 || len(parent2) != 1 || !parent1[0].IsEquiv(parent2[0]) {
+		t.Fatalf("Expected the decoded objects to share a parent")
+	}
+	parent1[0].Set("kind", "polygon")
+	if result := decoded[1].Get("kind").(string); result != "polygon" {
+		t.Fatalf("Expected %q but saw %v", "polygon", result)
+	}
+	sibling := decoded[1].Get("sibling").(goop.Object)
+	if !sibling.IsEquiv(decoded[0]) {
+		t.Fatalf("Expected the sibling reference to be preserved")
+	}
+}
@@ -150,44 +150,247 @@ invocation to a function that returns a constant value.
 package goop
 
 import "errors"
+import "fmt"
 import "reflect"
+import "sort"
+import "strconv"
+import "strings"
+import "sync"
+import "sync/atomic"
 
-// An object is represented internally as a struct.
+// An object is represented internally as a struct.  The prototypes
+// slice is never modified in place, only replaced, so a copy of the
+// slice header taken while holding the lock remains valid after the
+// lock is released.
 type internal struct {
-	symbolTable map[string]interface{} // Map from a member name to a member value
+	id          uint64                 // Unique identifier of the object
+	inArena     bool                   // true if the object's storage was allocated from an Arena's slab
+	symbolTable memberTable            // Map from a member name to a member value
 	prototypes  []Object               // List of other objects to search for members
+	dependents  map[string][]*computed // Map from a member name to the computed members that depend on it
+	watchers    map[string][]*watcher  // Map from a member name to the watchers of that member
+	hooks       map[string][]*hook     // Map from a method name to the hooks on that method
+	handlers    map[string][]*handler  // Map from an event name to the handlers of that event
+	shared      map[string]bool        // Set of members that descendants write through to
+	frozen      bool                   // true if the object's members and prototypes can no longer be modified
+	sealed      bool                   // true if members can no longer be added or removed
+	tableShared bool                   // true if symbolTable is shared with a Snapshot and must be copied before modification
+	history     *history               // Record of changes for Undo and Redo, or nil if not enabled
+	destroyed   bool                   // true if Destroy has been called
+	protoGen    uint64                 // Number of changes made to prototypes
+	lock        sync.RWMutex           // Lock protecting all of the above
+
+	isPrototype atomic.Bool                 // true if the object has served as another object's prototype
+	lookups     atomic.Pointer[lookupCache] // Owners of previously looked-up inherited members
+	readMostly  atomic.Bool                 // true if members are read from view without locking
+	view        atomic.Pointer[readView]    // Immutable copy of members for lock-free reads, or nil
+	tracer      atomic.Pointer[tracerBox]   // Tracer of the object's method invocations, or nil
+	schema      atomic.Pointer[Schema]      // Schema enforced on assignments to members, or nil
 }
 
 // ErrNotFound is returned by a failed attempt to locate an object member.
 var ErrNotFound = errors.New("Member not found")
 
+// ErrNoSuchMethod is returned by an attempt to invoke a method that
+// does not exist or is not a function.
+var ErrNoSuchMethod = errors.New("No such method")
+
+// A NotFoundError describes a failed attempt to locate a particular
+// object member.  errors.Is reports that a NotFoundError is
+// ErrNotFound and, if the member was sought as a method, also
+// ErrNoSuchMethod.
+type NotFoundError struct {
+	Member string // Name of the member
+	Method bool   // true if the member was sought as a method
+}
+
+// Error returns a description of a NotFoundError.
+func (e *NotFoundError) Error() string {
+	if e.Method {
+		return fmt.Sprintf("%s: %q", ErrNoSuchMethod, e.Member)
+	}
+	return fmt.Sprintf("%s: %q", ErrNotFound, e.Member)
+}
+
+// Is returns true if the target is ErrNotFound or, for a method, if
+// the target is ErrNoSuchMethod.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || (e.Method && target == ErrNoSuchMethod)
+}
+
+// ErrFrozen is returned by an attempt to modify a frozen object.
+var ErrFrozen = errors.New("Object is frozen")
+
+// ErrSealed is returned by an attempt to add a member to or remove a
+// member from a sealed object.
+var ErrSealed = errors.New("Object is sealed")
+
+// ErrNotNumeric is returned by an attempt to perform arithmetic on a
+// non-numeric object member.
+var ErrNotNumeric = errors.New("Member is not numeric")
+
+// ErrTypeMismatch is returned when a value's type does not match the
+// type of the object member to which it is applied.
+var ErrTypeMismatch = errors.New("Mismatched types")
+
+// ErrCycle is returned by an attempt to make an object its own
+// ancestor.
+var ErrCycle = errors.New("Cycle in prototype chain")
+
+// ErrBadArguments is returned by an attempt to invoke a method with
+// arguments it does not accept.
+var ErrBadArguments = errors.New("Arguments match no function signature")
+
+// ErrAmbiguous is returned by an attempt to invoke a MetaFunction
+// produced by CombineFunctionsWithPromotion with arguments that two or
+// more of its functions accept equally well.
+var ErrAmbiguous = errors.New("Arguments match multiple function signatures equally well")
+
+// ErrMultipleResults is returned by an attempt to retrieve a single
+// result from a method that returns more than one value.
+var ErrMultipleResults = errors.New("Method returned multiple values")
+
+// An ArgumentError describes an attempt to invoke a method with
+// arguments it does not accept.  It wraps ErrBadArguments.  errors.Is
+// additionally reports that an ArgumentError is ErrAmbiguous if more
+// than one function accepts the arguments equally well.
+type ArgumentError struct {
+	Method    string         // Name of the method, if known
+	Expected  []reflect.Type // Type of each function that could have been invoked
+	Actual    []reflect.Type // Type of each argument (nil for a nil argument)
+	Ambiguous bool           // true if each function in Expected accepts the arguments equally well
+}
+
+// Error returns a description of an ArgumentError.
+func (e *ArgumentError) Error() string {
+	expected := make([]string, len(e.Expected))
+	for i, t := range e.Expected {
+		expected[i] = t.String()
+	}
+	actual := make([]string, len(e.Actual))
+	for i, t := range e.Actual {
+		if t == nil {
+			actual[i] = "nil"
+		} else {
+			actual[i] = t.String()
+		}
+	}
+	msg := fmt.Sprintf("%s: expected %s but was passed (%s)",
+		ErrBadArguments, strings.Join(expected, " or "), strings.Join(actual, ", "))
+	if e.Ambiguous {
+		msg = fmt.Sprintf("%s: (%s) could be passed to %s",
+			ErrAmbiguous, strings.Join(actual, ", "), strings.Join(expected, " or "))
+	}
+	if e.Method != "" {
+		msg = fmt.Sprintf("Method %q: %s", e.Method, msg)
+	}
+	return msg
+}
+
+// Unwrap returns ErrBadArguments.
+func (e *ArgumentError) Unwrap() error {
+	return ErrBadArguments
+}
+
+// Is returns true if the target is ErrAmbiguous and the arguments
+// matched multiple functions equally well.
+func (e *ArgumentError) Is(target error) bool {
+	return e.Ambiguous && target == ErrAmbiguous
+}
+
+// A PanicError describes a method that panicked when invoked by Call,
+// CallErr, or a related method.  It wraps the panic value if that value
+// is an error.
+type PanicError struct {
+	Object Object         // Object on which the method was invoked
+	Method string         // Name of the method
+	Args   []reflect.Type // Type of each argument (nil for a nil argument)
+	Value  interface{}    // Value passed to panic
+}
+
+// Error returns a description of a PanicError.
+func (e *PanicError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, t := range e.Args {
+		if t == nil {
+			args[i] = "nil"
+		} else {
+			args[i] = t.String()
+		}
+	}
+	return fmt.Sprintf("Method %q panicked when passed (%s): %v",
+		e.Method, strings.Join(args, ", "), e.Value)
+}
+
+// Unwrap returns the panic value if it is an error and nil otherwise.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// newArgumentError returns an ArgumentError describing a failure of
+// any of the given function types to accept a list of arguments.
+func newArgumentError(methodName string, expected []reflect.Type, argList []interface{}) *ArgumentError {
+	return &ArgumentError{Method: methodName, Expected: expected, Actual: argumentTypes(argList)}
+}
+
+// argumentTypes returns the dynamic type of each argument in a list
+// (nil for a nil argument).
+func argumentTypes(argList []interface{}) []reflect.Type {
+	types := make([]reflect.Type, len(argList))
+	for i, arg := range argList {
+		types[i] = reflect.TypeOf(arg)
+	}
+	return types
+}
+
 // Object is a lot like a JavaScript object in that it uses prototype-based
-// inheritance instead of a class hierarchy.
+// inheritance instead of a class hierarchy.  Objects are safe for
+// concurrent use by multiple goroutines.  Note, however, that a
+// sequence of Get and Set calls is not atomic as a whole; use Add for
+// atomic updates of numeric members.
 type Object struct {
 	Implementation *internal // Internal representation not exposed to the user
 }
 
 // New allocates and return a new object.  It takes as arguments an
-// optional constructor function with optional arguments.
+// optional constructor function with optional arguments.  In place of
+// the constructor, New also accepts the name of a prototype registered
+// with Register, in which case the new object inherits from that
+// prototype and its Constructor method, if any, is invoked on the
+// arguments.  New panics with an *ArgumentError if the constructor
+// does not accept the new object followed by the given arguments.
 func New(constructor ...interface{}) Object {
-	// Allocate and initialize a new object.
-	obj := Object{}
-	obj.Implementation = &internal{}
-	obj.Implementation.symbolTable = make(map[string]interface{})
+	return construct(newObject(), constructor)
+}
 
+// construct implements New for a newly allocated object.
+func construct(obj Object, constructor []interface{}) Object {
 	// If we weren't given a constructor, we have nothing left to
 	// do.
 	if len(constructor) == 0 {
 		return obj
 	}
 
+	// Instantiate a registered prototype if given its name.
+	if name, ok := constructor[0].(string); ok {
+		return constructRegistered(obj, name, constructor[1:])
+	}
+
 	// Pass the new object and the given arguments to the
 	// constructor.  Ignore the constructor's return value(s).
 	constructorVal := reflect.ValueOf(constructor[0])
+	constructorType := constructorVal.Type()
+	argIfaces := append([]interface{}{obj}, constructor[1:]...)
+	if !acceptsArguments(constructorType, argIfaces) {
+		panic(newArgumentError("", []reflect.Type{constructorType}, argIfaces))
+	}
 	argList := make([]reflect.Value, len(constructor))
 	argList[0] = reflect.ValueOf(obj)
 	for i, argIface := range constructor[1:] {
-		argList[i+1] = reflect.ValueOf(argIface)
+		argList[i+1] = argumentValue(constructorType, i+1, argIface)
 	}
 	constructorVal.Call(argList)
 
@@ -195,29 +398,262 @@ func New(constructor ...interface{}) Object {
 	return obj
 }
 
+// NewSafe is like New but reports failures as an error rather than
+// panicking.  NewSafe returns an *ArgumentError if the constructor does
+// not accept the new object followed by the given arguments and an
+// error describing the panic if the constructor panics.  In the latter
+// case, the partially constructed object is returned along with the
+// error.
+func NewSafe(constructor interface{}, args ...interface{}) (obj Object, err error) {
+	// Ensure that the constructor accepts its arguments.
+	obj = newObject()
+	constructorVal := reflect.ValueOf(constructor)
+	if constructorVal.Kind() != reflect.Func {
+		return Object{}, fmt.Errorf("Constructor is a %T, not a function", constructor)
+	}
+	constructorType := constructorVal.Type()
+	argIfaces := append([]interface{}{obj}, args...)
+	if !acceptsArguments(constructorType, argIfaces) {
+		return Object{}, newArgumentError("", []reflect.Type{constructorType}, argIfaces)
+	}
+
+	// Invoke the constructor, converting a panic to an error.
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = fmt.Errorf("Constructor panicked: %w", rErr)
+			} else {
+				err = fmt.Errorf("Constructor panicked: %v", r)
+			}
+		}
+	}()
+	argList := make([]reflect.Value, len(argIfaces))
+	for i, argIface := range argIfaces {
+		argList[i] = argumentValue(constructorType, i, argIface)
+	}
+	constructorVal.Call(argList)
+	return obj, nil
+}
+
+// lastID is the most recently assigned object identifier.
+var lastID uint64
+
+// newObject allocates and returns a new, empty object.
+func newObject() Object {
+	obj := Object{}
+	obj.Implementation = &internal{id: newID()}
+	obj.Implementation.symbolTable = newMemberTable()
+	return obj
+}
+
+// Clone returns a new object with the same members and prototypes as
+// the original.  The copy is shallow: member values are copied as is,
+// so function members, slices, maps, pointers, and nested objects are
+// shared by reference with the original, and the clone's prototypes
+// are the very same objects as the original's.  Subsequent Sets and
+// Unsets on either object do not affect the other.  Computed members
+// are copied without their cached values.
+func (obj *Object) Clone() Object {
+	clone := newObject()
+	cImpl := clone.Implementation
+	impl := obj.Implementation
+	impl.lock.RLock()
+	defer impl.lock.RUnlock()
+	impl.symbolTable.each(func(key string, val interface{}) {
+		switch member := val.(type) {
+		case *computed:
+			cImpl.addComputed(key, member.deps, member.compute)
+		case *property:
+			cImpl.symbolTable.put(key, &property{desc: member.desc, value: member.get(), constant: member.constant})
+		default:
+			cImpl.symbolTable.put(key, val)
+		}
+	})
+	for key := range impl.shared {
+		cImpl.markShared(key)
+	}
+	cImpl.prototypes = impl.prototypes
+	return clone
+}
+
+// DeepClone is like Clone but additionally replaces each member whose
+// value is an object with a deep clone of that object.  If
+// cloneProtos is true, the object's prototypes are likewise replaced
+// by deep clones; otherwise, they are shared with the original.  An
+// object reachable via multiple paths is cloned only once, so the
+// clone preserves the sharing (and any cycles) among the original's
+// objects.
+func (obj *Object) DeepClone(cloneProtos bool) Object {
+	return obj.deepClone(cloneProtos, make(map[*internal]Object))
+}
+
+// deepClone implements DeepClone.  clones maps each object already
+// cloned to its clone.
+func (obj *Object) deepClone(cloneProtos bool, clones map[*internal]Object) Object {
+	if clone, ok := clones[obj.Implementation]; ok {
+		return clone
+	}
+	clone := obj.Clone()
+	clones[obj.Implementation] = clone
+
+	// The clone is not yet visible to any other goroutine so we
+	// can modify it without acquiring its lock.
+	cImpl := clone.Implementation
+	cImpl.symbolTable.each(func(key string, val interface{}) {
+		if nested, ok := val.(Object); ok && nested.Implementation != nil {
+			cImpl.symbolTable.put(key, nested.deepClone(cloneProtos, clones))
+		}
+	})
+	if cloneProtos {
+		prototypes := make([]Object, len(cImpl.prototypes))
+		for i, proto := range cImpl.prototypes {
+			prototypes[i] = proto.deepClone(cloneProtos, clones)
+		}
+		markPrototypes(prototypes)
+		cImpl.prototypes = prototypes
+	}
+	return clone
+}
+
+// Merge copies all of another object's members, including method
+// functions and inherited members, into the object itself.  Unlike
+// with SetSuper, the object retains no link to the source object, so
+// later changes to the source do not affect the object.  If overwrite
+// is false, members the object already contains are left untouched.
+// Computed members are copied as their current values.  Each member
+// is copied as if by Set, so Merge panics under the same conditions.
+func (obj *Object) Merge(src Object, overwrite bool) {
+	members := src.Contents(true)
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if overwrite || !obj.HasLocalMember(key) {
+			obj.Set(key, members[key])
+		}
+	}
+}
+
 // SetSuper specifies the object's parent object(s).  This is the
 // mechanism by which both single and multiple inheritance are
 // implemented.  For convenience, parents can be specified either
-// individually or as a slice.
+// individually or as a slice.  SetSuper panics with ErrCycle if the
+// object would become its own ancestor and with ErrFrozen if the object
+// is frozen.
 func (obj *Object) SetSuper(parentObjs ...interface{}) {
-	// Empty the current set of prototypes.
+	if err := obj.TrySetSuper(parentObjs...); err != nil {
+		panic(err)
+	}
+}
+
+// TrySetSuper is like SetSuper but returns ErrCycle or ErrFrozen
+// instead of panicking.  In that case, the object's parents are left
+// unchanged.
+func (obj *Object) TrySetSuper(parentObjs ...interface{}) error {
+	// Construct a new set of prototypes.
+	prototypes := objectList(parentObjs)
+	if obj.introducesCycle(prototypes) {
+		return ErrCycle
+	}
+	markPrototypes(prototypes)
+
+	// Replace the current set of prototypes with the new set.
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if impl.frozen {
+		return ErrFrozen
+	}
+	impl.recordHistory()
+	impl.prototypes = prototypes
+	impl.prototypesChanged()
+	return nil
+}
+
+// AddSuper appends one or more parent objects to the object's existing
+// list of parents.  As with SetSuper, parents can be specified either
+// individually or as a slice, and AddSuper panics with ErrCycle if the
+// object would become its own ancestor and with ErrFrozen if the
+// object is frozen.
+func (obj *Object) AddSuper(parentObjs ...interface{}) {
+	additions := objectList(parentObjs)
+	if obj.introducesCycle(additions) {
+		panic(ErrCycle)
+	}
+	markPrototypes(additions)
+
+	// Never modify the current prototypes in place as other
+	// goroutines may be searching them.
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if impl.frozen {
+		panic(ErrFrozen)
+	}
+	impl.recordHistory()
+	prototypes := make([]Object, 0, len(impl.prototypes)+len(additions))
+	prototypes = append(prototypes, impl.prototypes...)
+	impl.prototypes = append(prototypes, additions...)
+	impl.prototypesChanged()
+}
+
+// RemoveSuper removes a parent object from the object's list of
+// parents.  It returns true if the parent was found and false
+// otherwise.  RemoveSuper panics with ErrFrozen if the object is
+// frozen.
+func (obj *Object) RemoveSuper(parent Object) bool {
 	impl := obj.Implementation
-	impl.prototypes = make([]Object, 0, len(parentObjs))
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if impl.frozen {
+		panic(ErrFrozen)
+	}
+	for i, proto := range impl.prototypes {
+		if proto.IsEquiv(parent) {
+			impl.recordHistory()
+			prototypes := make([]Object, 0, len(impl.prototypes)-1)
+			prototypes = append(prototypes, impl.prototypes[:i]...)
+			impl.prototypes = append(prototypes, impl.prototypes[i+1:]...)
+			impl.prototypesChanged()
+			return true
+		}
+	}
+	return false
+}
 
-	// Append each prototype object in turn.
-	for _, parentIface := range parentObjs {
-		parentVal := reflect.ValueOf(parentIface)
-		switch parentVal.Type().Kind() {
+// objectList converts a list of objects and slices or arrays of
+// objects to a flat list of objects.
+func objectList(objIfaces []interface{}) []Object {
+	objs := make([]Object, 0, len(objIfaces))
+	for _, objIface := range objIfaces {
+		objVal := reflect.ValueOf(objIface)
+		switch objVal.Type().Kind() {
 		case reflect.Array, reflect.Slice:
-			// Append each object in turn to our prototype list.
-			for i := 0; i < parentVal.Len(); i++ {
-				impl.prototypes = append(impl.prototypes, parentVal.Index(i).Interface().(Object))
+			// Append each object in turn to our list.
+			for i := 0; i < objVal.Len(); i++ {
+				objs = append(objs, objVal.Index(i).Interface().(Object))
 			}
 		default:
-			// Append the individual object to our prototype list.
-			impl.prototypes = append(impl.prototypes, parentIface.(Object))
+			// Append the individual object to our list.
+			objs = append(objs, objIface.(Object))
+		}
+	}
+	return objs
+}
+
+// introducesCycle returns whether making the given objects parents of
+// the object would make the object its own ancestor.
+func (obj *Object) introducesCycle(parents []Object) bool {
+	for _, parent := range parents {
+		for _, ancestor := range parent.Ancestors(true) {
+			if ancestor.IsEquiv(*obj) {
+				return true
+			}
 		}
 	}
+	return false
 }
 
 // Super returns the object's parent object(s) as a list.
@@ -225,170 +661,1972 @@ func (obj *Object) Super() []Object {
 	// Return a copy of impl.prototypes so if the caller mucks
 	// with it, it won't mess up our object's internal
 	// representation.
-	protos := obj.Implementation.prototypes
+	protos := obj.Implementation.parents()
 	protoCopy := make([]Object, len(protos))
 	copy(protoCopy, protos)
 	return protoCopy
 }
 
+// parents returns the object's current list of prototypes.  The
+// caller must not modify the result.
+func (impl *internal) parents() []Object {
+	impl.lock.RLock()
+	protos := impl.prototypes
+	impl.lock.RUnlock()
+	return protos
+}
+
+// Ancestors returns all of the objects reachable from the object's
+// prototypes in the order in which Get would search them (depth
+// first, parents in the order given to SetSuper).  Each ancestor
+// appears only once, even if it is reachable via multiple paths, and
+// cycles in the prototype graph are tolerated.  If includeSelf is
+// true, the object itself appears first in the list.
+func (obj *Object) Ancestors(includeSelf bool) []Object {
+	visited := map[*internal]bool{obj.Implementation: true}
+	result := make([]Object, 0, 8)
+	if includeSelf {
+		result = append(result, *obj)
+	}
+	var walk func(o Object)
+	walk = func(o Object) {
+		for _, parent := range o.Implementation.parents() {
+			if visited[parent.Implementation] {
+				continue
+			}
+			visited[parent.Implementation] = true
+			result = append(result, parent)
+			walk(parent)
+		}
+	}
+	walk(*obj)
+	return result
+}
+
+// IsA returns whether another object is one of the object's ancestors
+// (i.e., appears anywhere in the list returned by Ancestors), much like
+// JavaScript's instanceof operator.  An object is not considered to be
+// its own ancestor.
+func (obj *Object) IsA(proto Object) bool {
+	for _, ancestor := range obj.Ancestors(false) {
+		if ancestor.IsEquiv(proto) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsEquiv returns whether another object is equivalent to the object
 // in question.
 func (obj *Object) IsEquiv(otherObj Object) bool {
 	return obj.Implementation == otherObj.Implementation
 }
 
-// Set associates an arbitrary value with the name of an object member.
-func (obj *Object) Set(memberName string, value interface{}) {
-	obj.Implementation.symbolTable[memberName] = value
+// ID returns a number that uniquely identifies the object for the
+// lifetime of the program, or 0 for an unallocated Object.  IDs are
+// assigned in order of allocation, so they also suffice to sort
+// objects stably.  Note that Object values are themselves comparable
+// with == (which agrees with IsEquiv) and can therefore serve directly
+// as map keys; ID is useful where a plain number is needed instead.
+func (obj *Object) ID() uint64 {
+	if obj.Implementation == nil {
+		return 0
+	}
+	return obj.Implementation.id
 }
 
-// Get returns the value associated with the name of an object member.
-func (obj *Object) Get(memberName string) (value interface{}) {
-	// Search our local members.
-	var ok bool
-	if value, ok = obj.Implementation.symbolTable[memberName]; ok {
-		return value
+// Equal returns whether another object has the same data members as
+// the object in question.  Unlike IsEquiv, which compares object
+// identity, Equal compares the members returned by Contents(false),
+// so inherited members participate in the comparison and method
+// functions do not.  Member values are compared with reflect.DeepEqual
+// except for member values that are themselves objects, which are
+// compared recursively with Equal.
+func (obj *Object) Equal(otherObj Object) bool {
+	return obj.EqualWith(otherObj, EqualOptions{})
+}
+
+// EqualOptions control the comparison performed by EqualWith.
+type EqualOptions struct {
+	Methods bool     // true to compare methods as well as data members
+	Ignore  []string // Names of members to exclude from the comparison at every level of nesting
+}
+
+// EqualWith is like Equal but accepts options that control the
+// comparison.  When Methods is true, two methods are considered equal
+// if they are the same Go function (for closures, the same function
+// literal) or the same MetaFunction.
+func (obj *Object) EqualWith(otherObj Object, opts EqualOptions) bool {
+	ignore := make(map[string]bool, len(opts.Ignore))
+	for _, name := range opts.Ignore {
+		ignore[name] = true
 	}
+	return obj.equal(otherObj, opts.Methods, ignore, make(map[[2]*internal]bool))
+}
 
-	// We didn't find the given member locally.  Try each of our
-	// parents in turn.
-	value = ErrNotFound
-	for _, parent := range obj.Implementation.prototypes {
-		parentValue := parent.Get(memberName)
-		if parentValue != ErrNotFound {
-			value = parentValue
-			return
+// equal implements EqualWith.  compared records the pairs of objects
+// already being compared so that cyclic object graphs terminate.
+func (obj *Object) equal(otherObj Object, methods bool, ignore map[string]bool, compared map[[2]*internal]bool) bool {
+	if obj.IsEquiv(otherObj) {
+		return true
+	}
+	if obj.Implementation == nil || otherObj.Implementation == nil {
+		return false
+	}
+	pair := [2]*internal{obj.Implementation, otherObj.Implementation}
+	if compared[pair] {
+		return true
+	}
+	compared[pair] = true
+	a := obj.Contents(methods)
+	b := otherObj.Contents(methods)
+	for key := range ignore {
+		delete(a, key)
+		delete(b, key)
+	}
+	if len(a) != len(b) {
+		return false
+	}
+	for key, aVal := range a {
+		bVal, ok := b[key]
+		if !ok {
+			return false
+		}
+		if !valuesEqual(aVal, bVal, methods, ignore, compared) {
+			return false
 		}
 	}
-	return
+	return true
 }
 
-// Unset removes a member from an object.  This function always
-// succeeds, even if the member did not previously exist.
-func (obj *Object) Unset(memberName string) {
-	delete(obj.Implementation.symbolTable, memberName)
+// valuesEqual compares two member values as described by EqualWith.
+func valuesEqual(aVal, bVal interface{}, methods bool, ignore map[string]bool, compared map[[2]*internal]bool) bool {
+	aObj, aIsObj := aVal.(Object)
+	bObj, bIsObj := bVal.(Object)
+	switch {
+	case aIsObj && bIsObj:
+		return aObj.equal(bObj, methods, ignore, compared)
+	case aIsObj || bIsObj:
+		return false
+	case reflect.ValueOf(aVal).Kind() == reflect.Func:
+		return sameFunction(aVal, bVal)
+	default:
+		return reflect.DeepEqual(aVal, bVal)
+	}
 }
 
-// Contents returns a map of all members of an object (useful for
-// iteration).  If the argument is true, Contents also includes method
-// functions.
-func (obj *Object) Contents(alsoMethods bool) map[string]interface{} {
-	// Copy our parents' data in reverse order so ancestor's
-	// members are correctly overridden.
-	impl := obj.Implementation
-	resultMap := make(map[string]interface{}, len(impl.symbolTable))
-	for i := len(impl.prototypes) - 1; i >= 0; i-- {
-		parentObj := impl.prototypes[i]
-		for key, val := range parentObj.Contents(alsoMethods) {
-			resultMap[key] = val
+// sameFunction returns true if two function values refer to the same
+// Go function or to MetaFunctions sharing the same dispatcher.
+func sameFunction(a, b interface{}) bool {
+	aVal := reflect.ValueOf(a)
+	bVal := reflect.ValueOf(b)
+	if !bVal.IsValid() || aVal.Type() != bVal.Type() {
+		return false
+	}
+	if aMF, ok := a.(MetaFunction); ok {
+		bMF := b.(MetaFunction)
+		if aMF.dispatcher() != nil || bMF.dispatcher() != nil {
+			return aMF.dispatcher() == bMF.dispatcher()
 		}
 	}
+	return aVal.Pointer() == bVal.Pointer()
+}
 
-	// Finally, copy our own object-specific data.
-	for key, val := range impl.symbolTable {
-		if alsoMethods || reflect.ValueOf(val).Kind() != reflect.Func {
-			resultMap[key] = val
+// IsAssignable returns whether object a can structurally stand in for
+// object b, that is, whether a provides every member that b provides
+// (including inherited members) with a compatible type.  A data
+// member is compatible if a's value is assignable to the type of b's
+// value.  A method is compatible if it accepts every argument b's
+// method accepts and returns values assignable to those b's method
+// returns.  Because a MetaFunction's signatures are determined only
+// at dispatch time, a MetaFunction is considered compatible only with
+// another MetaFunction.
+func IsAssignable(a, b Object) bool {
+	aMembers := a.Contents(true)
+	for name, bVal := range b.Contents(true) {
+		aVal, ok := aMembers[name]
+		if !ok || !isAssignableValue(aVal, bVal) {
+			return false
 		}
 	}
-	return resultMap
+	return true
 }
 
-// A typeDependentDispatch maps a textual type description to a
-// function that accepts the associated types.
-type typeDependentDispatch map[string]interface{}
+// isAssignableValue returns whether a member value a can replace a
+// member value b.
+func isAssignableValue(a, b interface{}) bool {
+	aType := reflect.TypeOf(a)
+	bType := reflect.TypeOf(b)
+	switch {
+	case bType == nil:
+		return true
+	case aType == nil:
+		return false
+	case aType.Kind() == reflect.Func && bType.Kind() == reflect.Func:
+		return isCompatibleFunc(aType, bType)
+	default:
+		return aType.AssignableTo(bType)
+	}
+}
 
-// Given a function, functionSignature returns a string that describes
-// its arguments.
-func functionSignature(funcIface interface{}) string {
-	funcType := reflect.ValueOf(funcIface).Type()
-	numArgs := funcType.NumIn()
-	argTypes := make([]byte, numArgs)
-	for i := 0; i < numArgs; i++ {
-		argTypes[i] = byte(funcType.In(i).Kind())
+// isCompatibleFunc returns whether a function of type a can be used
+// wherever a function of type b is expected.
+func isCompatibleFunc(a, b reflect.Type) bool {
+	// Handle the trivial case and the MetaFunction case.
+	metaType := reflect.TypeOf(MetaFunction(nil))
+	if a == b {
+		return true
+	}
+	if a == metaType || b == metaType {
+		return false
 	}
-	return string(argTypes)
+
+	// Ensure that a accepts all of b's arguments.
+	if a.NumIn() != b.NumIn() || a.IsVariadic() != b.IsVariadic() {
+		return false
+	}
+	for i := 0; i < a.NumIn(); i++ {
+		if !b.In(i).AssignableTo(a.In(i)) {
+			return false
+		}
+	}
+
+	// Ensure that all of a's return values can be used in place
+	// of b's.
+	if a.NumOut() != b.NumOut() {
+		return false
+	}
+	for i := 0; i < a.NumOut(); i++ {
+		if !a.Out(i).AssignableTo(b.Out(i)) {
+			return false
+		}
+	}
+	return true
 }
 
-// Given an array of arguments, argumentSignature returns a string
-// that describes them.
-func argumentSignature(argList []interface{}) string {
-	numArgs := len(argList)
-	argTypes := make([]byte, numArgs)
-	for i := 0; i < numArgs; i++ {
-		argTypes[i] = byte(reflect.TypeOf(argList[i]).Kind())
+// Set associates an arbitrary value with the name of an object member.
+// Set panics with ErrFrozen if the object is frozen, with ErrSealed if
+// the object is sealed and does not contain the member, with ErrReadOnly
+// if the member is computed or a read-only property, with a
+// *ReadOnlyError if the member is a constant (see SetConst), with
+// ErrTypeMismatch if the member is a field of a wrapped struct (see
+// Wrap) to which the value is not assignable, with the error returned
+// by a property's Set function (see DefineProperty), and with the error
+// Validate reports for a value that does not conform to a Schema
+// enforced on the object (see Enforce).
+func (obj *Object) Set(memberName string, value interface{}) {
+	if err := obj.TrySet(memberName, value); err != nil {
+		panic(err)
 	}
-	return string(argTypes)
 }
 
-// A MetaFunction encapsulates one or more functions, each with a
-// unique argument-type signature.  When a MetaFunction is invoked, it
-// accepts arbitrary inputs and returns arbitrary outputs (bundled
-// into a slice).  On failure to find a matching signature, a
-// singleton slice containing ErrNotFound is returned.
-type MetaFunction func(varArgs ...interface{}) (funcResult []interface{})
+// TrySet is like Set but returns an error instead of panicking.
+func (obj *Object) TrySet(memberName string, value interface{}) error {
+	if m := activeMetrics(); m != nil {
+		m.MemberWritten()
+	}
+	impl := obj.Implementation
+	if err := impl.checkSchema(memberName, value); err != nil {
+		return err
+	}
+	var validated *property
+	for {
+		old, watchers, redirect, err := impl.set(memberName, value, validated)
+		switch {
+		case err != nil:
+			return err
+		case redirect.owner.Implementation != nil:
+			owner := redirect.owner
+			return owner.TrySet(memberName, value)
+		case redirect.property != nil:
+			if err := redirect.property.validate(*obj, value); err != nil {
+				return err
+			}
+			validated = redirect.property
+			continue
+		}
+		notifyWatchers(watchers, old, value)
+		return nil
+	}
+}
 
-// CombineFunctions combines multiple functions into a single
-// MetaFunction for type-dependent dispatch.
-func CombineFunctions(functions ...interface{}) MetaFunction {
-	dispatchMap := make(typeDependentDispatch, len(functions))
-	for _, funcIface := range functions {
-		dispatchMap[functionSignature(funcIface)] = funcIface
+// A setRedirect describes an assignment that set cannot complete
+// while holding the object's lock.
+type setRedirect struct {
+	owner    Object    // Ancestor whose shared member should be assigned instead, if any
+	property *property // Property whose Set function must first accept the value, if any
+}
+
+// set associates a value with a member name and returns the member's
+// previous value (ErrNotFound if none) and the watchers to notify of
+// the change.  If the member is shared by an ancestor or is a
+// property with a Set function other than validated, set instead
+// returns a setRedirect without modifying the object.
+func (impl *internal) set(memberName string, value interface{}, validated *property) (interface{}, []*watcher, setRedirect, error) {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	old, ok := impl.symbolTable.get(memberName)
+	if !ok {
+		if owner, isShared := impl.sharedAncestor(memberName); isShared {
+			return nil, nil, setRedirect{owner: owner}, nil
+		}
 	}
-	dispatcher := func(varArgs ...interface{}) (funcResult []interface{}) {
-		// Find the function in the dispatch map.
-		funcIface, ok := dispatchMap[argumentSignature(varArgs)]
-		if !ok {
-			return []interface{}{ErrNotFound}
+	if err := impl.checkStored(memberName, old, ok); err != nil {
+		return nil, nil, setRedirect{}, err
+	}
+	switch member := old.(type) {
+	case *computed:
+		return nil, nil, setRedirect{}, ErrReadOnly
+	case *property:
+		if member.readOnly() {
+			return nil, nil, setRedirect{}, ErrReadOnly
+		}
+		if member.desc.Set != nil && member != validated {
+			return nil, nil, setRedirect{property: member}, nil
 		}
+		impl.recordHistory()
+		old = member.get()
+		member.set(value)
+		impl.memberChanged(memberName)
+		return old, impl.watchers[memberName], setRedirect{}, nil
+	case *structField:
+		snap := impl.prepareHistory()
+		old = member.get()
+		if err := member.set(value); err != nil {
+			return nil, nil, setRedirect{}, err
+		}
+		impl.commitHistory(snap)
+		impl.memberChanged(memberName)
+		return old, impl.watchers[memberName], setRedirect{}, nil
+	}
+	if !ok {
+		old = ErrNotFound
+	}
+	impl.recordHistory()
+	impl.ownTable()
+	impl.symbolTable.put(memberName, value)
+	if !ok {
+		impl.invalidateLookups()
+	}
+	impl.memberChanged(memberName)
+	return old, impl.watchers[memberName], setRedirect{}, nil
+}
+
+// Get returns the value associated with the name of an object member.
+// It returns ErrNotFound if the member does not exist.
+func (obj *Object) Get(memberName string) interface{} {
+	value, ok := obj.GetOK(memberName)
+	if !ok {
+		return ErrNotFound
+	}
+	return value
+}
+
+// GetDefault returns the value associated with the name of an object
+// member or fallback if the member does not exist.
+func (obj *Object) GetDefault(memberName string, fallback interface{}) interface{} {
+	value, ok := obj.GetOK(memberName)
+	if !ok {
+		return fallback
+	}
+	return value
+}
 
-		// Invoke the function.
-		funcValue := reflect.ValueOf(funcIface)
-		funcArgs := make([]reflect.Value, len(varArgs))
-		for i, arg := range varArgs {
-			funcArgs[i] = reflect.ValueOf(arg)
+// GetOK returns the value associated with the name of an object member
+// and true, or nil and false if the member does not exist.  Unlike
+// Get, GetOK can distinguish a missing member from a member whose
+// value happens to be ErrNotFound.
+func (obj *Object) GetOK(memberName string) (interface{}, bool) {
+	if m := activeMetrics(); m != nil {
+		m.MemberRead(obj.memberDepth(memberName))
+	}
+
+	// Search our local members.
+	impl := obj.Implementation
+	if view := impl.readView(); view != nil {
+		if value, ok := view.table.get(memberName); ok {
+			return memberValue(*obj, value), true
 		}
-		resultValues := funcValue.Call(funcArgs)
+		return impl.lookupInherited(*obj, view.prototypes, view.gen, memberName)
+	}
+	impl.lock.RLock()
+	value, ok := impl.symbolTable.get(memberName)
+	prototypes := impl.prototypes
+	gen := impl.protoGen
+	impl.lock.RUnlock()
+	if ok {
+		return memberValue(*obj, value), true
+	}
+
+	// We didn't find the given member locally.  Try each of our
+	// parents in turn.
+	return impl.lookupInherited(*obj, prototypes, gen, memberName)
+}
+
+// Resolve is like GetOK but additionally returns the object that
+// provides the member, which is either the object itself or the first
+// ancestor containing the member in the order in which Get searches.
+func (obj *Object) Resolve(memberName string) (owner Object, value interface{}, found bool) {
+	owner, stored, found := obj.resolveStored(memberName)
+	if !found {
+		return Object{}, nil, false
+	}
+	return owner, inheritedValue(*obj, owner, stored), true
+}
 
-		// Convert the function's return values to a more
-		// user-friendly type.
-		funcResult = make([]interface{}, len(resultValues))
-		for i, result := range resultValues {
-			funcResult[i] = result.Interface()
+// resolveStored is like Resolve but returns the value stored in the
+// owner's symbol table rather than the member's value.
+func (obj *Object) resolveStored(memberName string) (owner Object, stored interface{}, found bool) {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	stored, found = impl.symbolTable.get(memberName)
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	if found {
+		return *obj, stored, true
+	}
+	for _, parent := range prototypes {
+		if owner, stored, found = parent.resolveStored(memberName); found {
+			return owner, stored, true
 		}
-		return
 	}
-	return dispatcher
+	return Object{}, nil, false
 }
 
-// Call invokes a method on an object and returns the method's return
-// values as a slice.  Call returns a slice of the singleton ErrNotFound
-// if the method could not be found.
-func (obj *Object) Call(methodName string, arguments ...interface{}) []interface{} {
-	// Construct a function and its arguments, using Get to
-	// automatically search parent objects if necessary.
-	userFuncIface := obj.Get(methodName)
-	if userFuncIface == ErrNotFound {
-		return []interface{}{ErrNotFound}
+// memberValue returns the value of a member given the value stored in
+// an object's symbol table.  Computed members are evaluated, and
+// members backed by struct fields are read from the field.
+func memberValue(this Object, stored interface{}) interface{} {
+	switch member := stored.(type) {
+	case *computed:
+		return member.get(this)
+	case *structField:
+		return member.get()
+	case *property:
+		return member.read(this)
+	default:
+		return stored
 	}
-	userFunc := reflect.ValueOf(userFuncIface)
-	userFuncArgs := make([]reflect.Value, len(arguments)+1)
-	userFuncArgs[0] = reflect.ValueOf(*obj)
-	for i, argIface := range arguments {
-		userFuncArgs[i+1] = reflect.ValueOf(argIface)
+}
+
+// inheritedValue is like memberValue but for a member that the object
+// this either contains or inherits from owner.  Computed members and
+// property getters are evaluated on this, not on owner.  An inherited
+// computed member bypasses its cache, which holds the value computed
+// for owner.
+func inheritedValue(this, owner Object, stored interface{}) interface{} {
+	if c, ok := stored.(*computed); ok && owner.Implementation != this.Implementation {
+		return c.compute(this)
 	}
+	return memberValue(this, stored)
+}
 
-	// Call the function.
-	returnVals := userFunc.Call(userFuncArgs)
-	returnIfaces := make([]interface{}, len(returnVals))
-	for i, val := range returnVals {
-		returnIfaces[i] = val.Interface()
+// getInherited is like GetOK but ignores the object's own members.
+func (obj *Object) getInherited(memberName string) (interface{}, bool) {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	prototypes := impl.prototypes
+	gen := impl.protoGen
+	impl.lock.RUnlock()
+	return impl.lookupInherited(*obj, prototypes, gen, memberName)
+}
+
+// GetWithin is like GetOK but searches no more than maxDepth levels of
+// prototypes.  A maxDepth of 0 searches only the object itself, 1 also
+// searches the object's parents, 2 also searches its grandparents, and
+// so forth.  A negative maxDepth imposes no limit.
+func (obj *Object) GetWithin(memberName string, maxDepth int) (interface{}, bool) {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	value, ok := impl.symbolTable.get(memberName)
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	if ok {
+		return memberValue(*obj, value), true
+	}
+	if maxDepth == 0 {
+		return nil, false
 	}
+	for _, parent := range prototypes {
+		if value, ok := parent.GetWithin(memberName, maxDepth-1); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
 
-	// Return the results.  As a special case, we return a
-	// MetaFunction's already-wrapped results without an
-	// additional level of wrapping.
-	if _, ok := userFuncIface.(MetaFunction); ok {
-		returnIfaces = returnIfaces[0].([]interface{})
+// HasMember returns whether the object or any of its ancestors
+// contains the named member.  Unlike GetOK, HasMember does not
+// evaluate computed members.
+func (obj *Object) HasMember(memberName string) bool {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	_, ok := impl.symbolTable.get(memberName)
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	if ok {
+		return true
+	}
+	for _, parent := range prototypes {
+		if parent.HasMember(memberName) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLocalMember returns whether the object itself, ignoring its
+// ancestors, contains the named member.
+func (obj *Object) HasLocalMember(memberName string) bool {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	defer impl.lock.RUnlock()
+	_, ok := impl.symbolTable.get(memberName)
+	return ok
+}
+
+// Unset removes a member from an object.  This function succeeds even
+// if the member did not previously exist but panics with ErrFrozen if
+// the object is frozen, with ErrSealed if the object is sealed and
+// contains the member, and with a *ReadOnlyError if the member is a
+// constant (see SetConst).
+func (obj *Object) Unset(memberName string) {
+	if err := obj.TryUnset(memberName); err != nil {
+		panic(err)
+	}
+}
+
+// TryUnset is like Unset but returns an error instead of panicking.
+func (obj *Object) TryUnset(memberName string) error {
+	old, watchers, err := obj.Implementation.unset(memberName)
+	if err != nil || old == ErrNotFound {
+		return err
+	}
+	notifyWatchers(watchers, memberValue(*obj, old), ErrNotFound)
+	return nil
+}
+
+// unset removes a member and returns the member's previous value
+// (ErrNotFound if none) and the watchers to notify of the change.
+func (impl *internal) unset(memberName string) (interface{}, []*watcher, error) {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if impl.frozen {
+		return nil, nil, ErrFrozen
+	}
+	old, ok := impl.symbolTable.get(memberName)
+	if !ok {
+		return ErrNotFound, nil, nil
+	}
+	if impl.sealed {
+		return nil, nil, ErrSealed
+	}
+	if isConstant(old) {
+		return nil, nil, &ReadOnlyError{Member: memberName}
+	}
+	impl.recordHistory()
+	impl.removeComputed(memberName)
+	impl.ownTable()
+	impl.symbolTable.remove(memberName)
+	impl.invalidateLookups()
+	delete(impl.shared, memberName)
+	impl.memberChanged(memberName)
+	return old, impl.watchers[memberName], nil
+}
+
+// Freeze prevents all further modification of the object's members
+// and of its list of prototypes.  Subsequent attempts to Set or Unset
+// a member or to change the object's parents panic with ErrFrozen.
+// Freezing is shallow: the prototypes themselves are unaffected, and a
+// frozen object can still serve as the prototype of a mutable object.
+// Clones of a frozen object are not frozen.
+func (obj *Object) Freeze() {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	impl.frozen = true
+	impl.lock.Unlock()
+}
+
+// IsFrozen returns true if the object has been frozen.
+func (obj *Object) IsFrozen() bool {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	defer impl.lock.RUnlock()
+	return impl.frozen
+}
+
+// Seal prevents members from being added to or removed from the
+// object while still allowing the object's existing members to be
+// modified.  Subsequent attempts to Set a member the object does not
+// itself contain (including an inherited member) or to Unset a member
+// it does contain panic with ErrSealed.  As with Freeze, sealing is
+// shallow, and clones of a sealed object are not sealed.
+func (obj *Object) Seal() {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	impl.sealed = true
+	impl.lock.Unlock()
+}
+
+// IsSealed returns true if the object has been sealed.
+func (obj *Object) IsSealed() bool {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	defer impl.lock.RUnlock()
+	return impl.sealed
+}
+
+// checkDefine returns ErrFrozen if the object is frozen, ErrSealed if
+// the object is sealed and does not contain the named member, and a
+// *ReadOnlyError if the member is a constant (see SetConst).  The
+// caller must hold the object's lock.
+func (impl *internal) checkDefine(memberName string) error {
+	stored, ok := impl.symbolTable.get(memberName)
+	return impl.checkStored(memberName, stored, ok)
+}
+
+// checkStored is like checkDefine but is given the member's stored
+// value and whether the object contains the member.
+func (impl *internal) checkStored(memberName string, stored interface{}, ok bool) error {
+	if impl.frozen {
+		return ErrFrozen
+	}
+	if !ok && impl.sealed {
+		return ErrSealed
+	}
+	if isConstant(stored) {
+		return &ReadOnlyError{Member: memberName}
+	}
+	return nil
+}
+
+// Add atomically adds delta to a numeric member and returns the
+// member's new value.  delta must have the same type as the member's
+// current value.  If the member is inherited from a prototype, the sum
+// is stored in the object itself, just as with Set.  Add returns
+// ErrNotFound if the member does not exist, ErrNotNumeric if it is
+// not of a numeric type, ErrTypeMismatch if delta's type differs from
+// the member's, ErrFrozen if the object is frozen, and ErrSealed if
+// the object is sealed and does not itself contain the member.
+func (obj *Object) Add(memberName string, delta interface{}) (interface{}, error) {
+	if owner, ok := obj.sharedOwner(memberName); ok {
+		return owner.Add(memberName, delta)
+	}
+	old, sum, watchers, err := obj.Implementation.add(memberName, delta)
+	if err != nil {
+		return nil, err
+	}
+	notifyWatchers(watchers, old, sum)
+	return sum, nil
+}
+
+// add adds delta to a numeric member and returns the member's previous
+// value (ErrNotFound if inherited), its new value, and the watchers to
+// notify of the change.
+func (impl *internal) add(memberName string, delta interface{}) (interface{}, interface{}, []*watcher, error) {
+	// Find the member's current value.
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if err := impl.checkDefine(memberName); err != nil {
+		return nil, nil, nil, err
+	}
+	current, ok := impl.symbolTable.get(memberName)
+	switch member := current.(type) {
+	case *computed:
+		return nil, nil, nil, ErrReadOnly
+	case *property:
+		if member.hasAccessors() {
+			return nil, nil, nil, ErrReadOnly
+		}
+	}
+	field, isField := current.(storedValue)
+	if isField {
+		current = field.get()
+	}
+	old := current
+	if !ok {
+		old = ErrNotFound
+	}
+	for i := 0; !ok && i < len(impl.prototypes); i++ {
+		current, ok = impl.prototypes[i].GetOK(memberName)
+	}
+	if !ok {
+		return nil, nil, nil, ErrNotFound
+	}
+
+	// Add delta to the current value and store the result.
+	sum, err := addNumbers(reflect.ValueOf(current), reflect.ValueOf(delta))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	impl.recordHistory()
+	if isField {
+		field.set(sum)
+	} else {
+		impl.ownTable()
+		impl.symbolTable.put(memberName, sum)
+		if old == ErrNotFound {
+			impl.invalidateLookups()
+		}
+	}
+	impl.memberChanged(memberName)
+	return old, sum, impl.watchers[memberName], nil
+}
+
+// A storedValue is a member whose value is stored outside the symbol
+// table, such as a struct field or a property without accessors.
+type storedValue interface {
+	get() interface{}
+	set(value interface{}) error
+}
+
+// addNumbers returns the sum of two numeric values of the same type.
+func addNumbers(a, b reflect.Value) (interface{}, error) {
+	if !a.IsValid() {
+		return nil, ErrNotNumeric
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+	default:
+		return nil, ErrNotNumeric
+	}
+	if !b.IsValid() || a.Type() != b.Type() {
+		return nil, ErrTypeMismatch
+	}
+	sum := reflect.New(a.Type()).Elem()
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sum.SetInt(a.Int() + b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sum.SetUint(a.Uint() + b.Uint())
+	case reflect.Float32, reflect.Float64:
+		sum.SetFloat(a.Float() + b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		sum.SetComplex(a.Complex() + b.Complex())
+	}
+	return sum.Interface(), nil
+}
+
+// Contents returns a map of all members of an object (useful for
+// iteration).  If the argument is true, Contents also includes method
+// functions.
+func (obj *Object) Contents(alsoMethods bool) map[string]interface{} {
+	return obj.contents(*obj, alsoMethods)
+}
+
+// contents implements Contents for an object, this, that either is or
+// inherits from obj.
+func (obj *Object) contents(this Object, alsoMethods bool) map[string]interface{} {
+	// Take a snapshot of our own members and prototypes so we
+	// don't hold our lock while recursing into our parents.
+	impl := obj.Implementation
+	impl.lock.RLock()
+	local := impl.symbolTable.toMap()
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+
+	// Copy our parents' data in reverse order so ancestor's
+	// members are correctly overridden.
+	resultMap := make(map[string]interface{}, len(local))
+	for i := len(prototypes) - 1; i >= 0; i-- {
+		parentObj := prototypes[i]
+		for key, val := range parentObj.contents(this, alsoMethods) {
+			resultMap[key] = val
+		}
+	}
+
+	// Finally, copy our own object-specific data.
+	for key, val := range local {
+		if hidden(val) {
+			continue
+		}
+		val = inheritedValue(this, *obj, val)
+		if alsoMethods || reflect.ValueOf(val).Kind() != reflect.Func {
+			resultMap[key] = val
+		}
+	}
+	return resultMap
+}
+
+// Keys returns the names of all members of an object in lexical
+// order.  If the argument is true, Keys also includes the names of
+// method functions.  Keys includes the same names as Contents.  Unlike
+// Contents, it retrieves the values of only computed members,
+// properties, and fields of wrapped structs, and only to determine
+// whether they are method functions when the argument is false.
+func (obj *Object) Keys(alsoMethods bool) []string {
+	keySet := make(map[string]struct{})
+	obj.collectKeys(*obj, alsoMethods, keySet)
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// collectKeys adds the names of all members of an object, this, that
+// either is or inherits from obj to a set.
+func (obj *Object) collectKeys(this Object, alsoMethods bool, keySet map[string]struct{}) {
+	// Members whose values are produced by memberValue are examined
+	// after releasing our lock, as producing them may run user code.
+	var indirect map[string]interface{}
+	impl := obj.Implementation
+	impl.lock.RLock()
+	impl.symbolTable.each(func(key string, val interface{}) {
+		if hidden(val) {
+			return
+		}
+		switch val.(type) {
+		case *computed, *property, *structField:
+			if !alsoMethods {
+				if indirect == nil {
+					indirect = make(map[string]interface{})
+				}
+				indirect[key] = val
+				return
+			}
+		}
+		if alsoMethods || reflect.ValueOf(val).Kind() != reflect.Func {
+			keySet[key] = struct{}{}
+		}
+	})
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	for key, val := range indirect {
+		if reflect.ValueOf(inheritedValue(this, *obj, val)).Kind() != reflect.Func {
+			keySet[key] = struct{}{}
+		}
+	}
+	for _, parent := range prototypes {
+		parent.collectKeys(this, alsoMethods, keySet)
+	}
+}
+
+// A typeDependentDispatch maps a signature, as produced by
+// functionSignature or appendArgumentSignature, to a function that accepts
+// the associated types.  A nil entry indicates that no function
+// accepts the associated types.
+type typeDependentDispatch map[string]*dispatchTarget
+
+// typeIDs assigns each distinct type a small positive integer so that
+// signatures identify types exactly.  (Type names do not suffice, as
+// distinct types can share a name, for instance when declared in
+// different functions or in different packages with the same name.)
+var typeIDs = struct {
+	ids  map[reflect.Type]int // Map from a type to its identifier
+	lock sync.RWMutex         // Lock protecting ids
+}{ids: make(map[reflect.Type]int)}
+
+// typeID returns the identifier of a type.  A nil type (i.e., the
+// type of a nil argument) has identifier 0.
+func typeID(t reflect.Type) int {
+	if t == nil {
+		return 0
+	}
+	typeIDs.lock.RLock()
+	id, ok := typeIDs.ids[t]
+	typeIDs.lock.RUnlock()
+	if ok {
+		return id
+	}
+	typeIDs.lock.Lock()
+	defer typeIDs.lock.Unlock()
+	if id, ok = typeIDs.ids[t]; !ok {
+		id = len(typeIDs.ids) + 1
+		typeIDs.ids[t] = id
+	}
+	return id
+}
+
+// typeSignature returns a string that identifies a list of types.
+func typeSignature(types []reflect.Type) string {
+	ids := make([]string, len(types))
+	for i, t := range types {
+		ids[i] = strconv.Itoa(typeID(t))
+	}
+	return strings.Join(ids, ",")
+}
+
+// A dispatchTarget is a function to which a MetaFunction can dispatch,
+// with its reflection information precomputed.
+type dispatchTarget struct {
+	funcValue reflect.Value // The function itself
+	funcType  reflect.Type  // The function's type
+}
+
+// newDispatchTarget wraps a function in a dispatchTarget.
+func newDispatchTarget(funcIface interface{}) *dispatchTarget {
+	funcValue := reflect.ValueOf(funcIface)
+	return &dispatchTarget{funcValue: funcValue, funcType: funcValue.Type()}
+}
+
+// call invokes the target function on a list of arguments and returns
+// the function's results.  If the function is not variadic and takes
+// more parameters than there are arguments, the remaining parameters
+// receive their zero values.  If promote is true, numeric arguments
+// are promoted to their parameters' types.
+func (t *dispatchTarget) call(argList []interface{}, promote bool) []interface{} {
+	// Invoke the function.
+	numArgs := len(argList)
+	if !t.funcType.IsVariadic() && t.funcType.NumIn() > numArgs {
+		numArgs = t.funcType.NumIn()
+	}
+	valBuf := getValues(numArgs)
+	funcArgs := *valBuf
+	for i := range funcArgs {
+		switch {
+		case i >= len(argList):
+			funcArgs[i] = reflect.Zero(t.funcType.In(i))
+		case promote:
+			funcArgs[i] = promotedArgumentValue(t.funcType, i, argList[i])
+		default:
+			funcArgs[i] = argumentValue(t.funcType, i, argList[i])
+		}
+	}
+	resultValues := t.funcValue.Call(funcArgs)
+	putValues(valBuf)
+
+	// Convert the function's return values to a more
+	// user-friendly type.
+	funcResult := make([]interface{}, len(resultValues))
+	for i, result := range resultValues {
+		funcResult[i] = result.Interface()
+	}
+	return funcResult
+}
+
+// Given a function, functionSignature returns a string that identifies
+// the exact type of each of its parameters.
+func functionSignature(funcIface interface{}) string {
+	funcType := reflect.ValueOf(funcIface).Type()
+	paramTypes := make([]reflect.Type, funcType.NumIn())
+	for i := range paramTypes {
+		paramTypes[i] = funcType.In(i)
+	}
+	return typeSignature(paramTypes)
+}
+
+// Given an array of arguments, appendArgumentSignature appends to a
+// byte slice a signature that identifies the dynamic type of each
+// argument in the same format as functionSignature.  A nil argument
+// matches no function's signature exactly.  Looking up the byte slice
+// in a map via a string conversion does not allocate.
+func appendArgumentSignature(sig []byte, argList []interface{}) []byte {
+	for i, arg := range argList {
+		if i > 0 {
+			sig = append(sig, ',')
+		}
+		sig = strconv.AppendInt(sig, int64(typeID(reflect.TypeOf(arg))), 10)
+	}
+	return sig
+}
+
+// parameterType returns the type of a function's i-th parameter,
+// treating a variadic parameter as a sequence of parameters of its
+// element type.  It returns nil if the function has no i-th
+// parameter.
+func parameterType(funcType reflect.Type, i int) reflect.Type {
+	numParams := funcType.NumIn()
+	switch {
+	case funcType.IsVariadic() && i >= numParams-1:
+		return funcType.In(numParams - 1).Elem()
+	case i < numParams:
+		return funcType.In(i)
+	default:
+		return nil
+	}
+}
+
+// acceptsNil returns whether nil can be assigned to a value of a given
+// type.
+func acceptsNil(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface,
+		reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// argumentValue converts a function's i-th argument to a
+// reflect.Value.  A nil argument is converted to the zero value of
+// the corresponding parameter's type.
+func argumentValue(funcType reflect.Type, i int, arg interface{}) reflect.Value {
+	if arg == nil {
+		if paramType := parameterType(funcType, i); paramType != nil {
+			return reflect.Zero(paramType)
+		}
+	}
+	return reflect.ValueOf(arg)
+}
+
+// promotedArgumentValue is like argumentValue but promotes a numeric
+// argument that is not assignable to its parameter to the parameter's
+// type.  The caller must ensure that promotionCost permits the
+// promotion.
+func promotedArgumentValue(funcType reflect.Type, i int, arg interface{}) reflect.Value {
+	argValue := argumentValue(funcType, i, arg)
+	if arg == nil {
+		return argValue
+	}
+	paramType := parameterType(funcType, i)
+	if paramType != nil && !argValue.Type().AssignableTo(paramType) {
+		return argValue.Convert(paramType)
+	}
+	return argValue
+}
+
+// promotionCost returns the cost of passing an argument of a given
+// type to a parameter of another type and true, or 0 and false if the
+// argument cannot be passed to the parameter even with numeric
+// promotion.  An argument of identical type costs nothing, and one
+// that is assignable to the parameter (e.g., one that implements an
+// interface parameter) costs less than any promotion.  Only
+// predeclared numeric types are promoted, and only without loss of
+// range: integers to wider integers (signed to unsigned never, and
+// unsigned to signed only if strictly wider) or to floating-point
+// types, and float32 to float64.
+func promotionCost(argType, paramType reflect.Type) (int, bool) {
+	switch {
+	case argType == paramType:
+		return 0, true
+	case argType.AssignableTo(paramType):
+		return 1, true
+	case argType.PkgPath() != "" || paramType.PkgPath() != "":
+		return 0, false
+	}
+	argClass, argBits := numericClass(argType)
+	paramClass, paramBits := numericClass(paramType)
+	switch {
+	case argClass == 0 || paramClass == 0:
+		return 0, false
+	case argClass == paramClass && argBits <= paramBits:
+		return 2, true
+	case argClass == 'u' && paramClass == 'i' && argBits < paramBits:
+		return 3, true
+	case argClass != 'f' && paramClass == 'f':
+		return 4, true
+	default:
+		return 0, false
+	}
+}
+
+// numericClass returns 'i', 'u', or 'f' for a signed-integer,
+// unsigned-integer, or floating-point type, respectively, along with
+// the type's size in bits.  It returns 0 for all other types.
+func numericClass(t reflect.Type) (byte, int) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 'i', t.Bits()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return 'u', t.Bits()
+	case reflect.Float32, reflect.Float64:
+		return 'f', t.Bits()
+	default:
+		return 0, 0
+	}
+}
+
+// argumentsCost returns the total promotionCost of passing a list of
+// arguments to a function and true, or 0 and false if the function
+// does not accept the arguments even with numeric promotion.
+func argumentsCost(funcType reflect.Type, argList []interface{}) (int, bool) {
+	numParams := funcType.NumIn()
+	if funcType.IsVariadic() {
+		if len(argList) < numParams-1 {
+			return 0, false
+		}
+	} else if len(argList) != numParams {
+		return 0, false
+	}
+	total := 0
+	for i, arg := range argList {
+		paramType := parameterType(funcType, i)
+		if arg == nil {
+			if !acceptsNil(paramType) {
+				return 0, false
+			}
+			total++
+			continue
+		}
+		cost, ok := promotionCost(reflect.TypeOf(arg), paramType)
+		if !ok {
+			return 0, false
+		}
+		total += cost
+	}
+	return total, true
+}
+
+// A MetaFunction encapsulates one or more functions, each with a
+// unique argument-type signature.  When a MetaFunction is invoked, it
+// accepts arbitrary inputs and returns arbitrary outputs (bundled
+// into a slice).  On failure to find a matching signature, a
+// singleton slice containing ErrNotFound is returned.  The set of
+// functions underlying a MetaFunction produced by CombineFunctions can
+// be inspected with Signatures and modified with Add and Remove.
+type MetaFunction func(varArgs ...interface{}) (funcResult []interface{})
+
+// acceptsArguments returns whether a function accepts a given list of
+// arguments.  Each argument must be assignable to the corresponding
+// parameter, which includes implementing the parameter's type if that
+// type is an interface.  If the function is variadic, each argument
+// beyond the fixed parameters must be assignable to the type of the
+// variadic parameter's elements.  A nil argument is accepted by any
+// parameter whose type can be nil.
+func acceptsArguments(funcType reflect.Type, argList []interface{}) bool {
+	numParams := funcType.NumIn()
+	if funcType.IsVariadic() {
+		if len(argList) < numParams-1 {
+			return false
+		}
+	} else if len(argList) != numParams {
+		return false
+	}
+	return acceptsLeadingArguments(funcType, argList)
+}
+
+// acceptsLeadingArguments returns whether each argument in a list can
+// be passed to the corresponding parameter of a function, ignoring any
+// parameters beyond the end of the list.
+func acceptsLeadingArguments(funcType reflect.Type, argList []interface{}) bool {
+	for i, arg := range argList {
+		paramType := parameterType(funcType, i)
+		if paramType == nil {
+			return false
+		}
+		if arg == nil {
+			if !acceptsNil(paramType) {
+				return false
+			}
+		} else if !reflect.TypeOf(arg).AssignableTo(paramType) {
+			return false
+		}
+	}
+	return true
+}
+
+// A dispatcher selects and invokes a function based on the types of
+// its arguments.  It is the state underlying a MetaFunction produced
+// by CombineFunctions.  Because a MetaFunction may be shared by many
+// objects and invoked from many goroutines, the dispatcher's functions
+// and cache are protected by a lock.  The targets slice is never
+// modified in place, only replaced.
+type dispatcher struct {
+	targets     []*dispatchTarget     // All functions in the order given
+	defaults    bool                  // true if omitted trailing arguments default to zero values
+	promote     bool                  // true if numeric arguments are promoted and the best match wins
+	params      []string              // Names of the functions' parameters following the object, if registered
+	defaultArgs []interface{}         // Values for omitted trailing arguments, if registered
+	cache       typeDependentDispatch // Map from a signature to the function it resolves to
+	generation  uint64                // Number of changes to targets to date
+	lock        sync.RWMutex          // Lock protecting targets, cache, and generation
+}
+
+// maxDispatchCache bounds the number of signatures a dispatcher
+// caches, to keep a program that passes arguments of ever-changing
+// types from consuming unbounded memory.
+const maxDispatchCache = 1024
+
+// A dispatchQuery is passed to a MetaFunction produced by
+// metaFunction to request its underlying dispatcher instead of
+// invoking it.  Because the type is unexported, no user-supplied
+// argument list can be mistaken for a query.
+type dispatchQuery struct{}
+
+// resolve returns the function that should be invoked on a given list
+// of arguments, or nil if no function accepts the arguments.  If the
+// arguments are ambiguous, resolve instead returns nil and the
+// functions that accept the arguments equally well.
+func (d *dispatcher) resolve(argList []interface{}) (*dispatchTarget, []*dispatchTarget) {
+	// Look up the argument signature in the cache, which initially
+	// contains all of the exact signatures.
+	var scratch [64]byte
+	sig := appendArgumentSignature(scratch[:0], argList)
+	d.lock.RLock()
+	target, ok := d.cache[string(sig)]
+	targets := d.targets
+	generation := d.generation
+	d.lock.RUnlock()
+	if ok {
+		return target, nil
+	}
+	if m := activeMetrics(); m != nil {
+		m.DispatchMissed()
+	}
+
+	// Scan the functions for the first one that accepts the given
+	// arguments (or, when promoting, the best match), and cache the
+	// result (even if negative) unless the set of functions changed
+	// in the meantime.  Ambiguous results are not cached.
+	target = nil
+	if d.promote {
+		var tied []*dispatchTarget
+		target, tied = resolveBest(targets, argList)
+		if tied != nil {
+			return nil, tied
+		}
+	} else {
+		for _, t := range targets {
+			if acceptsArguments(t.funcType, argList) {
+				target = t
+				break
+			}
+		}
+	}
+	if target == nil && d.defaults {
+		target = resolveWithDefaults(targets, argList)
+	}
+	d.lock.Lock()
+	if d.generation == generation && len(d.cache) < maxDispatchCache {
+		d.cache[string(sig)] = target
+	}
+	d.lock.Unlock()
+	return target, nil
+}
+
+// resolveBest returns the function whose argumentsCost for a given
+// list of arguments is lowest, or nil if no function accepts the
+// arguments.  If multiple functions share the lowest cost, it instead
+// returns nil and all of those functions.
+func resolveBest(targets []*dispatchTarget, argList []interface{}) (*dispatchTarget, []*dispatchTarget) {
+	var best []*dispatchTarget
+	bestCost := 0
+	for _, t := range targets {
+		cost, ok := argumentsCost(t.funcType, argList)
+		switch {
+		case !ok:
+		case best == nil || cost < bestCost:
+			best = []*dispatchTarget{t}
+			bestCost = cost
+		case cost == bestCost:
+			best = append(best, t)
+		}
+	}
+	switch len(best) {
+	case 0:
+		return nil, nil
+	case 1:
+		return best[0], nil
+	default:
+		return nil, best
+	}
+}
+
+// resolveWithDefaults returns the non-variadic function with the
+// fewest parameters that accepts the given arguments followed by zero
+// values for its remaining parameters, or nil if there is no such
+// function.  Ties go to the function given first.
+func resolveWithDefaults(targets []*dispatchTarget, argList []interface{}) *dispatchTarget {
+	var best *dispatchTarget
+	for _, t := range targets {
+		numParams := t.funcType.NumIn()
+		if t.funcType.IsVariadic() || numParams <= len(argList) {
+			continue
+		}
+		if best != nil && numParams >= best.funcType.NumIn() {
+			continue
+		}
+		if acceptsLeadingArguments(t.funcType, argList) {
+			best = t
+		}
+	}
+	return best
+}
+
+// invoke calls the function that accepts a given list of arguments
+// and returns its results.  It returns an *ArgumentError if no function
+// accepts the arguments.
+func (d *dispatcher) invoke(argList []interface{}) ([]interface{}, error) {
+	target, tied := d.resolve(argList)
+	if tied != nil {
+		expected := make([]reflect.Type, len(tied))
+		for i, t := range tied {
+			expected[i] = t.funcType
+		}
+		argErr := newArgumentError("", expected, argList)
+		argErr.Ambiguous = true
+		return nil, argErr
+	}
+	if target == nil && d.defaultArgs != nil {
+		target, argList = d.resolveDefaultArgs(argList)
+	}
+	if target == nil {
+		return nil, newArgumentError("", d.functionTypes(), argList)
+	}
+	return target.call(argList, d.promote), nil
+}
+
+// resolveDefaultArgs returns the first non-variadic function that
+// accepts the given arguments followed by the trailing values
+// registered with WithDefaults for its remaining parameters, along
+// with the completed argument list.  It returns nil and the original
+// argument list if there is no such function.
+func (d *dispatcher) resolveDefaultArgs(argList []interface{}) (*dispatchTarget, []interface{}) {
+	d.lock.RLock()
+	targets := d.targets
+	defaultArgs := d.defaultArgs
+	d.lock.RUnlock()
+	for _, t := range targets {
+		missing := t.funcType.NumIn() - len(argList)
+		if t.funcType.IsVariadic() || missing <= 0 || missing > len(defaultArgs) {
+			continue
+		}
+		fullArgs := make([]interface{}, 0, len(argList)+missing)
+		fullArgs = append(fullArgs, argList...)
+		fullArgs = append(fullArgs, defaultArgs[len(defaultArgs)-missing:]...)
+		if acceptsArguments(t.funcType, fullArgs) {
+			return t, fullArgs
+		}
+	}
+	return nil, argList
+}
+
+// functionTypes returns the type of each of the dispatcher's functions.
+func (d *dispatcher) functionTypes() []reflect.Type {
+	d.lock.RLock()
+	targets := d.targets
+	d.lock.RUnlock()
+	types := make([]reflect.Type, len(targets))
+	for i, t := range targets {
+		types[i] = t.funcType
+	}
+	return types
+}
+
+// setTargets replaces the dispatcher's functions and resets its cache
+// to contain only their exact signatures.  The caller must hold the
+// dispatcher's lock.
+func (d *dispatcher) setTargets(targets []*dispatchTarget) {
+	d.targets = targets
+	d.cache = make(typeDependentDispatch, len(targets))
+	for _, t := range targets {
+		if !t.funcType.IsVariadic() {
+			d.cache[functionSignature(t.funcValue.Interface())] = t
+		}
+	}
+	d.generation++
+}
+
+// clone returns a copy of the dispatcher with an empty cache.
+func (d *dispatcher) clone() *dispatcher {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	c := &dispatcher{
+		defaults:    d.defaults,
+		promote:     d.promote,
+		params:      d.params,
+		defaultArgs: d.defaultArgs,
+	}
+	c.setTargets(d.targets)
+	return c
+}
+
+// mustDispatcher returns the dispatcher underlying a MetaFunction or
+// panics if the MetaFunction was not produced by CombineFunctions.
+func (mf MetaFunction) mustDispatcher() *dispatcher {
+	d := mf.dispatcher()
+	if d == nil {
+		panic(fmt.Errorf("MetaFunction was not produced by CombineFunctions"))
+	}
+	return d
+}
+
+// Add adds a function to those among which a MetaFunction produced by
+// CombineFunctions (or one of its variants) dispatches.  The function
+// is considered after all existing functions.  Because a MetaFunction
+// may be shared by many objects, the change is visible to every
+// object that refers to it.  Add panics if the MetaFunction was not
+// produced by CombineFunctions.
+func (mf MetaFunction) Add(function interface{}) {
+	d := mf.mustDispatcher()
+	target := newDispatchTarget(function)
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	targets := make([]*dispatchTarget, 0, len(d.targets)+1)
+	targets = append(targets, d.targets...)
+	d.setTargets(append(targets, target))
+}
+
+// Remove removes the function with the given signature, as returned by
+// Signatures, from those among which a MetaFunction dispatches.  It
+// returns true if such a function was found and false otherwise.
+// Remove panics if the MetaFunction was not produced by
+// CombineFunctions.
+func (mf MetaFunction) Remove(signature string) bool {
+	d := mf.mustDispatcher()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for i, t := range d.targets {
+		if t.funcType.String() == signature {
+			targets := make([]*dispatchTarget, 0, len(d.targets)-1)
+			targets = append(targets, d.targets[:i]...)
+			d.setTargets(append(targets, d.targets[i+1:]...))
+			return true
+		}
+	}
+	return false
+}
+
+// Signatures returns a human-readable description of the type of each
+// function among which a MetaFunction dispatches (e.g.,
+// "func(goop.Object, int) string"), in the order in which they are
+// considered.  It returns nil if the MetaFunction was not produced by
+// CombineFunctions.
+func (mf MetaFunction) Signatures() []string {
+	d := mf.dispatcher()
+	if d == nil {
+		return nil
+	}
+	types := d.functionTypes()
+	sigs := make([]string, len(types))
+	for i, t := range types {
+		sigs[i] = t.String()
+	}
+	return sigs
+}
+
+// dispatcher returns the dispatcher underlying a MetaFunction produced
+// by CombineFunctions or nil if the MetaFunction was constructed by
+// other means.  Only a MetaFunction whose code is dispatchCode is
+// queried, so a user-supplied MetaFunction is never invoked merely to
+// ask.
+func (mf MetaFunction) dispatcher() *dispatcher {
+	if mf == nil || reflect.ValueOf(mf).Pointer() != dispatchCode {
+		return nil
+	}
+	result := mf(dispatchQuery{})
+	if len(result) != 1 {
+		return nil
+	}
+	d, _ := result[0].(*dispatcher)
+	return d
+}
+
+// CombineFunctions combines multiple functions into a single
+// MetaFunction for type-dependent dispatch.  Functions are selected by
+// the full dynamic types of the arguments, not merely their kinds, in
+// the following order.  First, a non-variadic function whose parameter
+// types are identical to the argument types takes precedence.  Failing
+// that, the first function (in the order given) that accepts the
+// arguments is invoked.  A function accepts a list of arguments
+// if each argument is assignable to the corresponding parameter (as
+// when passing a *bytes.Buffer to an io.Writer parameter).  Variadic
+// functions accept any number of trailing arguments assignable to the
+// variadic parameter's element type.
+func CombineFunctions(functions ...interface{}) MetaFunction {
+	return combineFunctions(false, false, functions)
+}
+
+// CombineFunctionsWithDefaults is like CombineFunctions but lets a
+// caller omit trailing arguments.  If no function accepts the given
+// arguments, the non-variadic function with the fewest parameters
+// whose leading parameters accept the arguments is invoked, and its
+// remaining parameters receive their zero values.
+func CombineFunctionsWithDefaults(functions ...interface{}) MetaFunction {
+	return combineFunctions(true, false, functions)
+}
+
+// CombineFunctionsWithPromotion is like CombineFunctions but promotes
+// numeric arguments to wider numeric parameter types (e.g., int to
+// int64 or float64) and, rather than invoking the first function that
+// accepts the arguments, invokes the one that accepts them with the
+// least promotion.  An argument of a parameter's exact type is
+// preferred to one merely assignable to the parameter, which in turn
+// is preferred to an integer promoted to a wider integer type, then to
+// an unsigned integer promoted to a signed type, and finally to an
+// integer promoted to a floating-point type.  If two or more functions
+// accept the arguments equally well, the call fails with an
+// *ArgumentError that is ErrAmbiguous.
+func CombineFunctionsWithPromotion(functions ...interface{}) MetaFunction {
+	return combineFunctions(false, true, functions)
+}
+
+// combineFunctions implements CombineFunctions,
+// CombineFunctionsWithDefaults, and CombineFunctionsWithPromotion.
+func combineFunctions(defaults, promote bool, functions []interface{}) MetaFunction {
+	d := &dispatcher{defaults: defaults, promote: promote}
+	targets := make([]*dispatchTarget, len(functions))
+	for i, funcIface := range functions {
+		targets[i] = newDispatchTarget(funcIface)
+	}
+	d.setTargets(targets)
+	return d.metaFunction()
+}
+
+// dispatchCode is the code pointer of every MetaFunction returned by
+// metaFunction.
+var dispatchCode = reflect.ValueOf((*dispatcher)(nil).metaFunction()).Pointer()
+
+// metaFunction returns a MetaFunction that dispatches using the
+// dispatcher.  The MetaFunction is a method value, whose code is the
+// same for every dispatcher, unlike a closure, whose code the compiler
+// may duplicate when inlining.
+func (d *dispatcher) metaFunction() MetaFunction {
+	return d.dispatch
+}
+
+// dispatch invokes the function that accepts a list of arguments or,
+// given a dispatchQuery, returns the dispatcher itself.
+func (d *dispatcher) dispatch(varArgs ...interface{}) []interface{} {
+	if len(varArgs) == 1 {
+		if _, ok := varArgs[0].(dispatchQuery); ok {
+			return []interface{}{d}
+		}
+	}
+	funcResult, err := d.invoke(varArgs)
+	if err != nil {
+		return []interface{}{ErrNotFound}
+	}
+	return funcResult
+}
+
+// Overload is like Set but, if the object already has (or inherits) a
+// method with the given name, adds the function to that method's
+// overloads instead of replacing the method.  The object's member
+// becomes a new MetaFunction that dispatches among the existing
+// method's functions, minus any whose type is identical to the new
+// function's, followed by the new function.  If the existing method is
+// a MetaFunction produced by CombineFunctionsWithDefaults or
+// CombineFunctionsWithPromotion, the new MetaFunction behaves likewise.
+// Existing MetaFunctions are never modified, so overloading an
+// inherited method does not affect the prototype that provides it.
+// Overload panics under the same conditions as Set.
+func (obj *Object) Overload(memberName string, function interface{}) {
+	newType := reflect.TypeOf(function)
+	var defaults, promote bool
+	var functions []interface{}
+	existing, _ := obj.GetOK(memberName)
+	if mf, ok := existing.(MetaFunction); ok && mf.dispatcher() != nil {
+		d := mf.dispatcher()
+		d.lock.RLock()
+		defaults, promote = d.defaults, d.promote
+		for _, t := range d.targets {
+			if t.funcType != newType {
+				functions = append(functions, t.funcValue.Interface())
+			}
+		}
+		d.lock.RUnlock()
+	} else if existing != nil && reflect.TypeOf(existing).Kind() == reflect.Func {
+		if reflect.TypeOf(existing) != newType {
+			functions = append(functions, existing)
+		}
+	}
+	if len(functions) == 0 {
+		obj.Set(memberName, function)
+		return
+	}
+	obj.Set(memberName, combineFunctions(defaults, promote, append(functions, function)))
+}
+
+// Call invokes a method on an object and returns the method's return
+// values as a slice.  Call returns a slice of the singleton ErrNotFound
+// if neither the method nor a MethodMissing member could be found.
+// Call panics with an *ArgumentError if the method does not accept the
+// given arguments.  The object is passed to the method as its first
+// argument if the method's first parameter is of type Object; an
+// ordinary function (e.g., strings.ToUpper) receives only the given
+// arguments.  If the method itself panics, Call panics with a
+// *PanicError describing the object, the method, its arguments, and
+// the panic value.  Use CallErr to receive either failure as an error
+// instead.  Any hooks registered on the method with AddHook run around
+// the call.
+func (obj *Object) Call(methodName string, arguments ...interface{}) []interface{} {
+	if m := activeMetrics(); m != nil {
+		m.MethodCalled()
+	}
+	if hooks := obj.hooksFor(methodName); hooks != nil {
+		return runHooks(*obj, hooks, arguments, func(argList []interface{}) []interface{} {
+			return obj.call(methodName, argList)
+		})
+	}
+	return obj.call(methodName, arguments)
+}
+
+// call implements Call without running the method's hooks.
+func (obj *Object) call(methodName string, arguments []interface{}) []interface{} {
+	if t := obj.tracer(); t != nil {
+		return obj.traceCall(t, methodName, arguments, obj.callUntraced)
+	}
+	return obj.callUntraced(methodName, arguments)
+}
+
+// callUntraced implements call without notifying a Tracer.
+func (obj *Object) callUntraced(methodName string, arguments []interface{}) []interface{} {
+	// Use Get to automatically search parent objects if
+	// necessary.
+	userFuncIface := obj.Get(methodName)
+	if userFuncIface == ErrNotFound {
+		results, ok, err := obj.callMethodMissing(methodName, arguments)
+		if !ok {
+			return []interface{}{ErrNotFound}
+		}
+		return callResults(results, err)
+	}
+	return callResults(obj.callMethod(methodName, userFuncIface, arguments))
+}
+
+// callResults returns a method's results as Call does, raising an
+// error, such as an *ArgumentError or a *PanicError, as a panic.
+func callResults(results []interface{}, err error) []interface{} {
+	if err != nil {
+		panic(err)
+	}
+	return results
+}
+
+// CallSlice is like Call but expands its final argument, which must be
+// a slice, into individual arguments, much like the "..." in a Go
+// call such as f(x, ys...).  This makes it easy to pass a slice to a
+// variadic method.  CallSlice panics if the final argument is not a
+// slice.
+func (obj *Object) CallSlice(methodName string, arguments ...interface{}) []interface{} {
+	numArgs := len(arguments)
+	if numArgs == 0 {
+		panic(fmt.Errorf("CallSlice requires a final slice argument"))
+	}
+	tail := reflect.ValueOf(arguments[numArgs-1])
+	if tail.Kind() != reflect.Slice {
+		panic(fmt.Errorf("CallSlice requires a final slice argument, not %T", arguments[numArgs-1]))
+	}
+	argList := make([]interface{}, 0, numArgs-1+tail.Len())
+	argList = append(argList, arguments[:numArgs-1]...)
+	for i := 0; i < tail.Len(); i++ {
+		argList = append(argList, tail.Index(i).Interface())
+	}
+	return obj.Call(methodName, argList...)
+}
+
+// MethodMissing names the member that Call, CallErr, and the functions
+// built on them invoke in place of a method that cannot be found.  The
+// member is passed the name of the missing method followed by the
+// method's arguments, so a typical definition has the form
+//
+//	func(this goop.Object, name string, args ...interface{}) []interface{}
+//
+// and can forward the call to another object or synthesize a method on
+// demand.  A single []interface{} result, as in the above, is returned
+// to the caller as the missing method's list of results.
+const MethodMissing = "__methodMissing__"
+
+// callMethodMissing invokes the object's MethodMissing function in
+// place of the named method.  The second return value is false if the
+// object has no such function (or if the missing method is itself
+// MethodMissing).
+func (obj *Object) callMethodMissing(methodName string, arguments []interface{}) ([]interface{}, bool, error) {
+	if methodName == MethodMissing {
+		return nil, false, nil
+	}
+	handler, ok := obj.GetOK(MethodMissing)
+	if !ok {
+		return nil, false, nil
+	}
+	argList := append([]interface{}{methodName}, arguments...)
+	results, err := obj.callMethod(MethodMissing, handler, argList)
+	if err != nil {
+		return nil, true, err
+	}
+	if len(results) == 1 {
+		if wrapped, ok := results[0].([]interface{}); ok {
+			results = wrapped
+		}
+	}
+	return results, true, nil
+}
+
+// objectType is the reflected type of an Object.
+var objectType = reflect.TypeOf(Object{})
+
+// takesThis returns whether a method function expects the object on
+// which it is invoked as its first argument.  This is true of
+// MetaFunctions and of functions whose first parameter is an Object.
+func takesThis(funcIface interface{}, funcType reflect.Type) bool {
+	if _, ok := funcIface.(MetaFunction); ok {
+		return true
+	}
+	return funcType.NumIn() > 0 && funcType.In(0) == objectType
+}
+
+// callMethod invokes a method function on the object.  The object is
+// passed as the function's first argument only if the function
+// expects it.  callMethod returns an *ArgumentError if the function
+// does not accept its arguments and a *PanicError if it panics.
+func (obj *Object) callMethod(methodName string, userFuncIface interface{}, arguments []interface{}) (results []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			results = nil
+			err = &PanicError{Object: *obj, Method: methodName, Args: argumentTypes(arguments), Value: r}
+		}
+	}()
+
+	// Invoke functions of common signatures without reflection.
+	if results, ok := callFast(*obj, userFuncIface, arguments); ok {
+		return results, nil
+	}
+	return obj.callReflect(methodName, userFuncIface, arguments)
+}
+
+// callReflect implements callMethod for functions that callFast cannot
+// invoke.
+func (obj *Object) callReflect(methodName string, userFuncIface interface{}, arguments []interface{}) ([]interface{}, error) {
+	// Ensure that the function accepts its arguments.
+	userFunc := reflect.ValueOf(userFuncIface)
+	userFuncType := userFunc.Type()
+	if userFuncType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("Method %q: %w (member is a %T)", methodName, ErrNoSuchMethod, userFuncIface)
+	}
+	argList := arguments
+	if takesThis(userFuncIface, userFuncType) {
+		buf := withThis(*obj, arguments)
+		defer putArgList(buf)
+		argList = *buf
+	}
+	if !acceptsArguments(userFuncType, argList) {
+		return nil, newArgumentError(methodName, []reflect.Type{userFuncType}, argList)
+	}
+
+	// Dispatch a MetaFunction produced by CombineFunctions directly
+	// rather than via reflection.
+	if mf, ok := userFuncIface.(MetaFunction); ok {
+		if d := mf.dispatcher(); d != nil {
+			results, err := d.invoke(argList)
+			if err != nil {
+				return []interface{}{ErrNotFound}, nil
+			}
+			return results, nil
+		}
+	}
+
+	// Construct the function's arguments.
+	valBuf := getValues(len(argList))
+	userFuncArgs := *valBuf
+	for i, argIface := range argList {
+		userFuncArgs[i] = argumentValue(userFuncType, i, argIface)
+	}
+
+	// Call the function.
+	returnVals := userFunc.Call(userFuncArgs)
+	putValues(valBuf)
+	returnIfaces := make([]interface{}, len(returnVals))
+	for i, val := range returnVals {
+		returnIfaces[i] = val.Interface()
+	}
+
+	// Return the results.  As a special case, we return a
+	// MetaFunction's already-wrapped results without an
+	// additional level of wrapping.
+	if _, ok := userFuncIface.(MetaFunction); ok {
+		returnIfaces = returnIfaces[0].([]interface{})
+	}
+	return returnIfaces, nil
+}
+
+// CallErr is like Call but reports failures as an error rather than
+// as a method result.  It returns a *NotFoundError (which is both
+// ErrNotFound and ErrNoSuchMethod) if neither the method nor a
+// MethodMissing member could be found, an error wrapping
+// ErrNoSuchMethod if the member is not a function, and an
+// *ArgumentError (which is ErrBadArguments) if the method (or, for a
+// MetaFunction, every function it combines) does not accept the given
+// arguments.  A method that returns ErrNotFound as a result is thereby
+// distinguished from a method that does not exist.  If the method
+// panics, CallErr recovers and returns a *PanicError describing the
+// method, its arguments, and the panic value.  Any hooks registered on
+// the method with AddHook run around the call; a failure to invoke the
+// method is reported to them as a nil list of results.
+func (obj *Object) CallErr(methodName string, arguments ...interface{}) ([]interface{}, error) {
+	return obj.callErrWith(methodName, arguments, (*Object).callErrUntraced)
+}
+
+// callErrWith implements CallErr given a function that invokes a
+// method on an object without running the method's hooks, notifying a
+// Tracer, or recovering from panics.
+func (obj *Object) callErrWith(methodName string, arguments []interface{}, invoke func(obj *Object, methodName string, arguments []interface{}) ([]interface{}, error)) (results []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			results = nil
+			err = &PanicError{Object: *obj, Method: methodName, Args: argumentTypes(arguments), Value: r}
+		}
+	}()
+	if m := activeMetrics(); m != nil {
+		m.MethodCalled()
+	}
+	if hooks := obj.hooksFor(methodName); hooks != nil {
+		var callErr error
+		results = runHooks(*obj, hooks, arguments, func(argList []interface{}) []interface{} {
+			var results []interface{}
+			results, callErr = obj.callErr(methodName, argList, invoke)
+			return results
+		})
+		if callErr != nil {
+			return nil, callErr
+		}
+		return results, nil
+	}
+	return obj.callErr(methodName, arguments, invoke)
+}
+
+// callErr implements callErrWith without running the method's hooks
+// or recovering from panics.
+func (obj *Object) callErr(methodName string, arguments []interface{}, invoke func(obj *Object, methodName string, arguments []interface{}) ([]interface{}, error)) ([]interface{}, error) {
+	if t := obj.tracer(); t != nil {
+		var err error
+		results := obj.traceCall(t, methodName, arguments, func(methodName string, arguments []interface{}) []interface{} {
+			var results []interface{}
+			results, err = invoke(obj, methodName, arguments)
+			return results
+		})
+		return results, err
+	}
+	return invoke(obj, methodName, arguments)
+}
+
+// callErrUntraced implements callErr without notifying a Tracer.
+func (obj *Object) callErrUntraced(methodName string, arguments []interface{}) ([]interface{}, error) {
+	userFuncIface, ok := obj.GetOK(methodName)
+	if !ok {
+		results, ok, err := obj.callMethodMissing(methodName, arguments)
+		if !ok {
+			return nil, &NotFoundError{Member: methodName, Method: true}
+		}
+		return results, err
+	}
+	if mf, ok := userFuncIface.(MetaFunction); ok {
+		if d := mf.dispatcher(); d != nil {
+			buf := withThis(*obj, arguments)
+			results, err := d.invoke(*buf)
+			putArgList(buf)
+			if err != nil {
+				err.(*ArgumentError).Method = methodName
+				return nil, err
+			}
+			return results, nil
+		}
+	}
+	return obj.callMethod(methodName, userFuncIface, arguments)
+}
+
+// Call1 is like CallErr but for methods that return at most one value.
+// It returns the method's result or nil if the method returns nothing.
+// Call1 returns an error wrapping ErrMultipleResults if the method
+// returns more than one value.
+func (obj *Object) Call1(methodName string, arguments ...interface{}) (interface{}, error) {
+	results, err := obj.CallErr(methodName, arguments...)
+	switch {
+	case err != nil:
+		return nil, err
+	case len(results) == 0:
+		return nil, nil
+	case len(results) > 1:
+		return nil, fmt.Errorf("Method %q: %w (%d)", methodName, ErrMultipleResults, len(results))
+	default:
+		return results[0], nil
+	}
+}
+
+// CallAll invokes every method with the given name defined by the
+// object itself or by any of its ancestors, in the order in which Get
+// searches them, and returns each method's results.  Each method is
+// passed the object itself, as with CallSuper.  Objects that do not
+// define the method are skipped, so CallAll returns an empty list if
+// the method is not found.  Like Call, CallAll panics with an
+// *ArgumentError if a method does not accept the given arguments and
+// with a *PanicError if a method panics.
+func (obj *Object) CallAll(methodName string, arguments ...interface{}) [][]interface{} {
+	var allResults [][]interface{}
+	for _, definer := range obj.Ancestors(true) {
+		impl := definer.Implementation
+		impl.lock.RLock()
+		userFuncIface, ok := impl.symbolTable.get(methodName)
+		impl.lock.RUnlock()
+		if !ok {
+			continue
+		}
+		userFuncIface = inheritedValue(*obj, definer, userFuncIface)
+		if reflect.ValueOf(userFuncIface).Kind() != reflect.Func {
+			continue
+		}
+		allResults = append(allResults, callResults(obj.callMethod(methodName, userFuncIface, arguments)))
+	}
+	return allResults
+}
+
+// Bind returns a function that invokes the named method on the object
+// as if by Call.  Bind returns a *NotFoundError if the method does not
+// exist.  The method is looked up anew each time the returned function
+// is invoked, so the function reflects later
+// redefinitions of the method (and returns a slice of ErrNotFound if
+// the method is later Unset).
+func (obj *Object) Bind(methodName string) (func(args ...interface{}) []interface{}, error) {
+	return obj.BindPartial(methodName)
+}
+
+// BindPartial is like Bind but additionally fixes the method's leading
+// arguments.  The returned function passes presetArgs followed by its
+// own arguments to the method.
+func (obj *Object) BindPartial(methodName string, presetArgs ...interface{}) (func(args ...interface{}) []interface{}, error) {
+	if !obj.HasMember(methodName) {
+		return nil, &NotFoundError{Member: methodName, Method: true}
+	}
+	return Bind(*obj, methodName, presetArgs...), nil
+}
+
+// Bind returns a function that invokes the named method on an object
+// with some leading arguments already supplied, as with the object's
+// BindPartial method.  Unlike BindPartial, Bind does not check that
+// the method exists; a missing method behaves as with Call.  Because
+// the returned function invokes the method via Call, it composes with
+// MetaFunction dispatch on the combined argument list.
+func Bind(obj Object, methodName string, presetArgs ...interface{}) func(args ...interface{}) []interface{} {
+	preset := append([]interface{}(nil), presetArgs...)
+	return func(args ...interface{}) []interface{} {
+		allArgs := make([]interface{}, 0, len(preset)+len(args))
+		allArgs = append(allArgs, preset...)
+		allArgs = append(allArgs, args...)
+		return obj.Call(methodName, allArgs...)
+	}
+}
+
+// Method returns a function that invokes the named method on the
+// object, suitable for passing as a callback.  Unlike Bind, Method
+// looks up the method only once, so the returned function continues
+// to invoke the method's current definition even if the method is
+// later redefined or Unset.  If the method does not yet exist, the
+// returned function instead invokes Call each time, as Bind does.  As
+// with Call, the returned function panics with an *ArgumentError if
+// the method does not accept its arguments and with a *PanicError if
+// the method panics.
+func (obj *Object) Method(methodName string) func(args ...interface{}) []interface{} {
+	self := *obj
+	userFuncIface, ok := self.GetOK(methodName)
+	if !ok {
+		return func(args ...interface{}) []interface{} {
+			return self.Call(methodName, args...)
+		}
+	}
+	return func(args ...interface{}) []interface{} {
+		return callResults(self.callMethod(methodName, userFuncIface, args))
+	}
+}
+
+// CallSuper is like Call but begins the search for the method with
+// the object's parents, skipping the object's own members.  This lets
+// a method that overrides an inherited method invoke the method it
+// overrides.  The object itself, not the parent, is passed to the
+// method.  Note that if the inherited method in turn calls CallSuper,
+// the search again begins with the object's own parents, not the
+// parents of the object that provided the inherited method.  Like
+// Call, CallSuper panics with an *ArgumentError if the method does not
+// accept the given arguments and with a *PanicError if the method
+// panics.
+func (obj *Object) CallSuper(methodName string, arguments ...interface{}) []interface{} {
+	userFuncIface, ok := obj.getInherited(methodName)
+	if !ok {
+		return []interface{}{ErrNotFound}
 	}
-	return returnIfaces
+	return callResults(obj.callMethod(methodName, userFuncIface, arguments))
 }
@@ -3,8 +3,14 @@
 package goop_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/lanl/goop"
+	"io"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +47,61 @@ func TestNonexistent(t *testing.T) {
 	}
 }
 
+// Test distinguishing missing members from members with sentinel
+// values.
+func TestGetOK(t *testing.T) {
+	parent := goop.New()
+	parent.Set("err", goop.ErrNotFound)
+	obj := goop.New()
+	obj.SetSuper(parent)
+	obj.Set("nil", nil)
+	if value, ok := obj.GetOK("err"); !ok || value != goop.ErrNotFound {
+		t.Fatalf("Expected (%v, true) but saw (%v, %v)", goop.ErrNotFound, value, ok)
+	}
+	if value, ok := obj.GetOK("nil"); !ok || value != nil {
+		t.Fatalf("Expected (nil, true) but saw (%v, %v)", value, ok)
+	}
+	if value, ok := obj.GetOK("bogus"); ok {
+		t.Fatalf("Unexpectedly found member \"bogus\" with value %v", value)
+	}
+}
+
+// Test checking for inherited and local members.
+func TestHasMember(t *testing.T) {
+	parent := goop.New()
+	parent.Set("inherited", goop.ErrNotFound)
+	obj := goop.New()
+	obj.SetSuper(parent)
+	obj.Set("local", nil)
+	if !obj.HasMember("inherited") || obj.HasLocalMember("inherited") {
+		t.Fatalf("Expected %q to be inherited", "inherited")
+	}
+	if !obj.HasMember("local") || !obj.HasLocalMember("local") {
+		t.Fatalf("Expected %q to be local", "local")
+	}
+	if obj.HasMember("bogus") || obj.HasLocalMember("bogus") {
+		t.Fatalf("Unexpectedly found member %q", "bogus")
+	}
+}
+
+// Test retrieving members with a fallback value.
+func TestGetDefault(t *testing.T) {
+	parent := goop.New()
+	parent.Set("port", 8080)
+	obj := goop.New()
+	obj.SetSuper(parent)
+	obj.Set("host", "localhost")
+	if result := obj.GetDefault("host", "example.com").(string); result != "localhost" {
+		t.Fatalf("Expected %q but saw %v", "localhost", result)
+	}
+	if result := obj.GetDefault("port", 80).(int); result != 8080 {
+		t.Fatalf("Expected %d but saw %v", 8080, result)
+	}
+	if result := obj.GetDefault("timeout", 30).(int); result != 30 {
+		t.Fatalf("Expected %d but saw %v", 30, result)
+	}
+}
+
 // Test creating and invoking a do-nothing method with no function
 // arguments or return value.
 func TestDoNothingFunction(t *testing.T) {
@@ -60,6 +121,25 @@ func TestDoubleFunction(t *testing.T) {
 	}
 }
 
+// Test invoking ordinary functions that do not take the object as
+// their first argument.
+func TestPlainFunction(t *testing.T) {
+	obj := goop.New()
+	obj.Set("triple", func(x int) int { return x * 3 })
+	obj.Set("upper", strings.ToUpper)
+	obj.Set("add", func(self goop.Object, x int) int { return self.Get("base").(int) + x })
+	obj.Set("base", 100)
+	if result := obj.Call("triple", 5)[0].(int); result != 15 {
+		t.Fatalf("Expected %d but saw %v", 15, result)
+	}
+	if result := obj.Call("upper", "goop")[0].(string); result != "GOOP" {
+		t.Fatalf("Expected %q but saw %v", "GOOP", result)
+	}
+	if result := obj.Call("add", 5)[0].(int); result != 105 {
+		t.Fatalf("Expected %d but saw %v", 105, result)
+	}
+}
+
 // Test invoking a method that modifies object state.
 func TestModifyObj(t *testing.T) {
 	obj := goop.New()
@@ -75,6 +155,147 @@ func TestModifyObj(t *testing.T) {
 	}
 }
 
+// Test preventing modification of a frozen object.
+func TestFreeze(t *testing.T) {
+	// Freeze a parent object.
+	parent := goop.New()
+	parent.Set("x", 1)
+	parent.Set("getX", func(self goop.Object) int { return self.Get("x").(int) })
+	parent.Freeze()
+	if !parent.IsFrozen() {
+		t.Fatalf("Expected the object to be frozen")
+	}
+
+	// Ensure that the frozen object can be read but not modified.
+	if err := parent.TrySet("x", 2); err != goop.ErrFrozen {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, err)
+	}
+	if err := parent.TryUnset("x"); err != goop.ErrFrozen {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, err)
+	}
+	if result := parent.Call("getX")[0].(int); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if err := parent.TrySetSuper(goop.New()); err != goop.ErrFrozen {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, err)
+	}
+
+	// Ensure that a child of the frozen object is mutable.
+	child := goop.New()
+	child.SetSuper(parent)
+	child.Set("x", 3)
+	if child.IsFrozen() {
+		t.Fatalf("Expected the child not to be frozen")
+	}
+	if result := child.Call("getX")[0].(int); result != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, result)
+	}
+
+	// Ensure that Set panics on a frozen object.
+	defer func() {
+		if r := recover(); r != goop.ErrFrozen {
+			t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, r)
+		}
+	}()
+	parent.Set("x", 4)
+}
+
+// Test preventing members from being added or removed.
+func TestSeal(t *testing.T) {
+	parent := goop.New()
+	parent.Set("y", 2)
+	obj := goop.New()
+	obj.SetSuper(parent)
+	obj.Set("x", 1)
+	obj.Seal()
+	if !obj.IsSealed() {
+		t.Fatalf("Expected the object to be sealed")
+	}
+
+	// Ensure that existing members can be modified but members
+	// cannot be added or removed.
+	if err := obj.TrySet("x", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := obj.TrySet("z", 3); err != goop.ErrSealed {
+		t.Fatalf("Expected %v but saw %v", goop.ErrSealed, err)
+	}
+	if err := obj.TrySet("y", 3); err != goop.ErrSealed {
+		t.Fatalf("Expected %v but saw %v", goop.ErrSealed, err)
+	}
+	if err := obj.TryUnset("x"); err != goop.ErrSealed {
+		t.Fatalf("Expected %v but saw %v", goop.ErrSealed, err)
+	}
+	if result := obj.Get("x").(int); result != 5 {
+		t.Fatalf("Expected %d but saw %v", 5, result)
+	}
+}
+
+// Test atomically incrementing numeric members.
+func TestAdd(t *testing.T) {
+	// Increment a counter from multiple goroutines at once.
+	obj := goop.New()
+	obj.Set("count", 0)
+	const numAdds = 1000
+	done := make(chan bool)
+	for i := 0; i < numAdds; i++ {
+		go func() {
+			obj.Add("count", 1)
+			done <- true
+		}()
+	}
+	for i := 0; i < numAdds; i++ {
+		<-done
+	}
+	if result := obj.Get("count").(int); result != numAdds {
+		t.Fatalf("Expected %d but saw %v", numAdds, result)
+	}
+
+	// Ensure that invalid additions are rejected.
+	obj.Set("name", "counter")
+	if _, err := obj.Add("name", 1); err != goop.ErrNotNumeric {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotNumeric, err)
+	}
+	if _, err := obj.Add("count", 1.5); err != goop.ErrTypeMismatch {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+	if _, err := obj.Add("bogus", 1); err != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, err)
+	}
+}
+
+// Test concurrently accessing an object and its prototypes.
+func TestConcurrentAccess(t *testing.T) {
+	parent := goop.New()
+	parent.Set("base", 1)
+	child := goop.New()
+	child.SetSuper(parent)
+	child.Set("sum", func(this goop.Object, x int) int {
+		return this.Get("base").(int) + x
+	})
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			for j := 0; j < 100; j++ {
+				child.Set("x", j)
+				child.Get("x")
+				child.Call("sum", j)
+				child.Contents(true)
+				parent.Set("base", i)
+				child.Unset("x")
+				child.SetSuper(parent)
+			}
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+	if result := child.Get("base"); result == goop.ErrNotFound {
+		t.Fatalf("Expected to find member \"base\"")
+	}
+}
+
 // Test iterating over all members.
 func TestIteration(t *testing.T) {
 	// Add various datatypes to an object.
@@ -124,6 +345,26 @@ func TestIteration(t *testing.T) {
 	}
 }
 
+// Test listing member names in order.
+func TestKeys(t *testing.T) {
+	parent := goop.New()
+	parent.Set("b", 1)
+	parent.Set("d", 2)
+	obj := goop.New()
+	obj.SetSuper(parent)
+	obj.Set("c", 3)
+	obj.Set("b", 4)
+	obj.Set("a", func(this goop.Object) {})
+	expected := "b c d"
+	if result := strings.Join(obj.Keys(false), " "); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+	expected = "a b c d"
+	if result := strings.Join(obj.Keys(true), " "); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+}
+
 // Test constructors.
 func TestConstructors(t *testing.T) {
 	happyClass := func(self goop.Object, someVal int) {
@@ -134,6 +375,44 @@ func TestConstructors(t *testing.T) {
 	if result := happyObj.Get("val"); result.(int) != value {
 		t.Fatalf("Expected %d but saw %v", value, result)
 	}
+
+	// Ensure that arguments are not converted to the constructor's
+	// parameter types.
+	for _, arg := range []interface{}{3.9, "x"} {
+		func() {
+			defer func() {
+				if r, ok := recover().(error); !ok || !errors.Is(r, goop.ErrBadArguments) {
+					t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, r)
+				}
+			}()
+			goop.New(happyClass, arg)
+		}()
+	}
+	if _, err := goop.NewSafe(func(self goop.Object, s string) {}, 65); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test reporting constructor failures as errors.
+func TestNewSafe(t *testing.T) {
+	class := func(self goop.Object, someVal interface{}) {
+		self.Set("partial", true)
+		self.Set("val", someVal.(int))
+	}
+	obj, err := goop.NewSafe(class, 5)
+	if err != nil || obj.Get("val").(int) != 5 {
+		t.Fatalf("Expected a constructed object but saw %v", err)
+	}
+	if _, err = goop.NewSafe(class); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+	obj, err = goop.NewSafe(class, "five")
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Fatalf("Expected a constructor panic but saw %v", err)
+	}
+	if result := obj.Get("partial").(bool); !result {
+		t.Fatalf("Expected the partially constructed object to be returned")
+	}
 }
 
 // Test single-parent inheritance.
@@ -172,6 +451,71 @@ func TestSingleParentInheritance(t *testing.T) {
 	}
 }
 
+// Test invoking an overridden method from the overriding method.
+func TestCallSuper(t *testing.T) {
+	point2DClass := func(self goop.Object, x, y int) {
+		self.Set("x", x)
+		self.Set("y", y)
+		self.Set("toString", func(this goop.Object) string {
+			return fmt.Sprintf("x=%d y=%d", this.Get("x"), this.Get("y"))
+		})
+	}
+	point3DClass := func(self goop.Object, x, y, z int) {
+		self.SetSuper(goop.New(point2DClass, x, y))
+		self.Set("z", z)
+		self.Set("toString", func(this goop.Object) string {
+			return this.CallSuper("toString")[0].(string) + fmt.Sprintf(" z=%d", this.Get("z"))
+		})
+	}
+	point3D := goop.New(point3DClass, 1, 2, 3)
+	point3D.Set("x", 10)
+	expected := "x=10 y=2 z=3"
+	if result := point3D.Call("toString")[0].(string); result != expected {
+		t.Fatalf("Expected %q but saw %q", expected, result)
+	}
+	if result := point3D.CallSuper("bogus"); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound but received %#v", result)
+	}
+}
+
+// Test invoking every definition of a method along the prototype
+// chain.
+func TestCallAll(t *testing.T) {
+	// Construct a diamond in which the root, one parent, and the
+	// child define a handler.
+	handler := func(name string) func(goop.Object, int) string {
+		return func(self goop.Object, n int) string {
+			return fmt.Sprintf("%s %d %s", name, n, self.Get("id"))
+		}
+	}
+	root := goop.New()
+	root.Set("onUpdate", handler("root"))
+	left := goop.New()
+	left.SetSuper(root)
+	right := goop.New()
+	right.SetSuper(root)
+	right.Set("onUpdate", handler("right"))
+	child := goop.New()
+	child.SetSuper(left, right)
+	child.Set("onUpdate", handler("child"))
+	child.Set("id", "c")
+
+	// Ensure that each handler runs once in Get's search order.
+	expected := []string{"child 1 c", "root 1 c", "right 1 c"}
+	results := child.CallAll("onUpdate", 1)
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results but saw %d", len(expected), len(results))
+	}
+	for i, str := range expected {
+		if results[i][0].(string) != str {
+			t.Fatalf("Expected %q but saw %v", str, results[i][0])
+		}
+	}
+	if results := child.CallAll("bogus"); len(results) != 0 {
+		t.Fatalf("Expected no results but saw %v", results)
+	}
+}
+
 // Test dynamically changing an object's lineage.
 func TestSuperChange(t *testing.T) {
 	parentType1 := func(self goop.Object) {
@@ -208,6 +552,511 @@ func TestSuperChange(t *testing.T) {
 	}
 }
 
+// Test adding and removing individual parents.
+func TestAddRemoveSuper(t *testing.T) {
+	base := goop.New()
+	base.Set("kind", "base")
+	mixin1 := goop.New()
+	mixin1.Set("one", 1)
+	mixin2 := goop.New()
+	mixin2.Set("two", 2)
+	obj := goop.New()
+	obj.SetSuper(base)
+
+	// Ensure that AddSuper appends to the existing parents.
+	obj.AddSuper([]goop.Object{mixin1, mixin2})
+	if result := obj.Super(); len(result) != 3 || !result[0].IsEquiv(base) || !result[2].IsEquiv(mixin2) {
+		t.Fatalf("Expected 3 parents but saw %#v", result)
+	}
+	if result := obj.Get("two").(int); result != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, result)
+	}
+
+	// Ensure that RemoveSuper removes only the given parent.
+	if !obj.RemoveSuper(mixin2) {
+		t.Fatalf("Expected RemoveSuper to find its argument")
+	}
+	if obj.RemoveSuper(mixin2) {
+		t.Fatalf("Expected RemoveSuper not to find a removed parent")
+	}
+	if result := obj.Get("two"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	if result := obj.Get("one").(int); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if result := obj.Get("kind").(string); result != "base" {
+		t.Fatalf("Expected %q but saw %v", "base", result)
+	}
+}
+
+// Test enumerating all of an object's ancestors.
+func TestAncestors(t *testing.T) {
+	// Construct a diamond with an extra back edge to form a cycle.
+	// SetSuper rejects cycles, so the back edge is added unchecked.
+	root := goop.New()
+	left := goop.New()
+	left.SetSuper(root)
+	right := goop.New()
+	right.SetSuper(root)
+	child := goop.New()
+	child.SetSuper(left, right)
+	goop.SetSuperUnchecked(root, child)
+
+	// Ensure that each ancestor appears once and in Get's search
+	// order.
+	expected := []goop.Object{left, root, right}
+	result := child.Ancestors(false)
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d ancestors but saw %d", len(expected), len(result))
+	}
+	for i, obj := range expected {
+		if !result[i].IsEquiv(obj) {
+			t.Fatalf("Ancestor %d is not the expected object", i)
+		}
+	}
+	if result := child.Ancestors(true); len(result) != 4 || !result[0].IsEquiv(child) {
+		t.Fatalf("Expected the object itself to appear first in %#v", result)
+	}
+}
+
+// Test recognizing an object's ancestors.
+func TestIsA(t *testing.T) {
+	// Construct a diamond.
+	root := goop.New()
+	left := goop.New()
+	left.SetSuper(root)
+	right := goop.New()
+	right.SetSuper(root)
+	child := goop.New()
+	child.SetSuper(left, right)
+
+	// Ensure that IsA recognizes exactly the object's ancestors.
+	if !child.IsA(root) || !child.IsA(right) {
+		t.Fatalf("Expected the child to descend from root and right")
+	}
+	if child.IsA(child) || root.IsA(child) || left.IsA(right) {
+		t.Fatalf("Unexpectedly found a non-ancestor")
+	}
+}
+
+// Test limiting the depth of a prototype search.
+func TestGetWithin(t *testing.T) {
+	// Construct a diamond with a member defined only at the root.
+	root := goop.New()
+	root.Set("depth", 2)
+	left := goop.New()
+	left.SetSuper(root)
+	right := goop.New()
+	right.SetSuper(root)
+	right.Set("side", "right")
+	child := goop.New()
+	child.SetSuper(left, right)
+
+	// Ensure that members beyond the depth limit are not found.
+	if _, ok := child.GetWithin("side", 0); ok {
+		t.Fatalf("Unexpectedly found member %q at depth 0", "side")
+	}
+	if value, ok := child.GetWithin("side", 1); !ok || value != "right" {
+		t.Fatalf("Expected (%q, true) but saw (%v, %v)", "right", value, ok)
+	}
+	if _, ok := child.GetWithin("depth", 1); ok {
+		t.Fatalf("Unexpectedly found member %q at depth 1", "depth")
+	}
+	for _, maxDepth := range []int{2, -1} {
+		if value, ok := child.GetWithin("depth", maxDepth); !ok || value != 2 {
+			t.Fatalf("Expected (%d, true) but saw (%v, %v)", 2, value, ok)
+		}
+	}
+}
+
+// Test writing through to members shared by all descendants.
+func TestSetShared(t *testing.T) {
+	// Define a class with a shared counter and an unshared name.
+	class := goop.New()
+	class.SetShared("count", 0)
+	class.Set("name", "class")
+	inst1 := goop.New()
+	inst1.SetSuper(class)
+	inst2 := goop.New()
+	inst2.SetSuper(class)
+
+	// Ensure that both instances update the shared counter.
+	inst1.Add("count", 1)
+	inst2.Set("count", inst2.Get("count").(int)+1)
+	for _, obj := range []goop.Object{class, inst1, inst2} {
+		if result := obj.Get("count").(int); result != 2 {
+			t.Fatalf("Expected %d but saw %v", 2, result)
+		}
+	}
+	if inst1.HasLocalMember("count") {
+		t.Fatalf("Expected %q not to be stored in the instance", "count")
+	}
+
+	// Ensure that unshared members are still overridden.
+	inst1.Set("name", "inst1")
+	if result := inst2.Get("name").(string); result != "class" {
+		t.Fatalf("Expected %q but saw %v", "class", result)
+	}
+}
+
+// Test finding the object that provides a member.
+func TestResolve(t *testing.T) {
+	// Construct a diamond in which both the root and the right
+	// parent define a member.
+	root := goop.New()
+	root.Set("name", "root")
+	left := goop.New()
+	left.SetSuper(root)
+	right := goop.New()
+	right.SetSuper(root)
+	right.Set("name", "right")
+	child := goop.New()
+	child.SetSuper(left, right)
+	child.Set("own", 1)
+
+	// Ensure that Resolve follows Get's search order.
+	if owner, value, found := child.Resolve("name"); !found || !owner.IsEquiv(root) || value != "root" {
+		t.Fatalf("Expected (root, %q, true) but saw (%v, %v, %v)", "root", owner, value, found)
+	}
+	if owner, value, found := child.Resolve("own"); !found || !owner.IsEquiv(child) || value != 1 {
+		t.Fatalf("Expected (child, %d, true) but saw (%v, %v, %v)", 1, owner, value, found)
+	}
+	if _, _, found := child.Resolve("bogus"); found {
+		t.Fatalf("Unexpectedly resolved member %q", "bogus")
+	}
+}
+
+// Test rejecting cycles in the prototype chain.
+func TestSuperCycle(t *testing.T) {
+	// Ensure that TrySetSuper rejects a two-object cycle.
+	a := goop.New()
+	b := goop.New()
+	a.SetSuper(b)
+	if err := b.TrySetSuper(a); err != goop.ErrCycle {
+		t.Fatalf("Expected %v but saw %v", goop.ErrCycle, err)
+	}
+	if err := a.TrySetSuper([]goop.Object{a}); err != goop.ErrCycle {
+		t.Fatalf("Expected %v but saw %v", goop.ErrCycle, err)
+	}
+	if result := b.Get("bogus"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+
+	// Ensure that SetSuper panics on a cycle.
+	defer func() {
+		if r := recover(); r != goop.ErrCycle {
+			t.Fatalf("Expected %v but saw %v", goop.ErrCycle, r)
+		}
+	}()
+	b.SetSuper(a)
+}
+
+// Test identifying objects by ID and using them as map keys.
+func TestID(t *testing.T) {
+	a := goop.New()
+	b := a.Clone()
+	if a.ID() == 0 || a.ID() == b.ID() {
+		t.Fatalf("Expected distinct, nonzero IDs but saw %d and %d", a.ID(), b.ID())
+	}
+	alias := a
+	if alias.ID() != a.ID() {
+		t.Fatalf("Expected %d but saw %d", a.ID(), alias.ID())
+	}
+	set := map[goop.Object]bool{a: true}
+	if !set[alias] || set[b] {
+		t.Fatalf("Expected objects to be keyed by identity")
+	}
+}
+
+// Test storing, removing, and retrieving many members, including
+// members added to similar objects in different orders.
+func TestManyMembers(t *testing.T) {
+	const numMembers = 200
+	forward := goop.New()
+	backward := goop.New()
+	for i := 0; i < numMembers; i++ {
+		forward.Set(fmt.Sprint("m", i), i)
+		backward.Set(fmt.Sprint("m", numMembers-1-i), numMembers-1-i)
+	}
+	forward.Unset("m0")
+	if forward.HasMember("m0") {
+		t.Fatalf("Unexpectedly found member %q", "m0")
+	}
+	for i := 1; i < numMembers; i++ {
+		name := fmt.Sprint("m", i)
+		if result := forward.Get(name).(int); result != i {
+			t.Fatalf("Expected %d but saw %v", i, result)
+		}
+		if result := backward.Get(name).(int); result != i {
+			t.Fatalf("Expected %d but saw %v", i, result)
+		}
+	}
+	if keys := backward.Keys(false); len(keys) != numMembers {
+		t.Fatalf("Expected %d but saw %d", numMembers, len(keys))
+	}
+}
+
+// Test objects with enough members that their storage is sharded.
+func TestHugeObject(t *testing.T) {
+	const numMembers = 20000
+	obj := goop.New()
+	for i := 0; i < numMembers; i++ {
+		obj.Set(fmt.Sprint("m", i), i)
+	}
+	snap := obj.Snapshot()
+	for i := 0; i < numMembers; i += 2 {
+		obj.Unset(fmt.Sprint("m", i))
+	}
+	obj.Set("m1", -1)
+	if keys := obj.Keys(false); len(keys) != numMembers/2 {
+		t.Fatalf("Expected %d but saw %d", numMembers/2, len(keys))
+	}
+	if result := obj.Get("m1"); result != -1 {
+		t.Fatalf("Expected %d but saw %v", -1, result)
+	}
+	if obj.HasMember("m0") {
+		t.Fatalf("Unexpectedly found member %q", "m0")
+	}
+
+	// The snapshot must be unaffected by the changes.
+	obj.Restore(snap)
+	if keys := obj.Keys(false); len(keys) != numMembers {
+		t.Fatalf("Expected %d but saw %d", numMembers, len(keys))
+	}
+	for i := 0; i < numMembers; i++ {
+		if result := obj.Get(fmt.Sprint("m", i)); result != i {
+			t.Fatalf("Expected %d but saw %v", i, result)
+		}
+	}
+}
+
+// Test comparing objects' data members for equality.
+func TestEqual(t *testing.T) {
+	// Construct two points, one of which inherits a member.
+	origin := goop.New()
+	origin.Set("z", 0)
+	p1 := goop.New()
+	p1.SetSuper(origin)
+	p1.Set("x", 1)
+	p1.Set("y", []int{2, 3})
+	p1.Set("describe", func(self goop.Object) string { return "p1" })
+	p2 := goop.New()
+	p2.Set("x", 1)
+	p2.Set("y", []int{2, 3})
+	p2.Set("z", 0)
+
+	// Ensure that equal points compare equal even though they are
+	// not equivalent.
+	if !p1.Equal(p2) || !p2.Equal(p1) {
+		t.Fatalf("Expected %v to equal %v", p1.Contents(false), p2.Contents(false))
+	}
+	if p1.IsEquiv(p2) {
+		t.Fatalf("Expected distinct objects not to be equivalent")
+	}
+
+	// Ensure that nested objects are compared recursively.
+	color1 := goop.New()
+	color1.Set("name", "red")
+	color2 := goop.New()
+	color2.Set("name", "red")
+	p1.Set("color", color1)
+	p2.Set("color", color2)
+	if !p1.Equal(p2) {
+		t.Fatalf("Expected nested objects to compare equal")
+	}
+	color2.Set("name", "blue")
+	if p1.Equal(p2) {
+		t.Fatalf("Expected nested objects to compare unequal")
+	}
+
+	// Ensure that ignored members are skipped at every level.
+	if !p1.EqualWith(p2, goop.EqualOptions{Ignore: []string{"name"}}) {
+		t.Fatalf("Expected objects to compare equal when ignoring %q", "name")
+	}
+
+	// Ensure that methods are compared when requested.
+	color2.Set("name", "red")
+	if p1.EqualWith(p2, goop.EqualOptions{Methods: true}) {
+		t.Fatalf("Expected objects with different methods to compare unequal")
+	}
+	p2.Set("describe", p1.Get("describe"))
+	if !p1.EqualWith(p2, goop.EqualOptions{Methods: true}) {
+		t.Fatalf("Expected objects with the same methods to compare equal")
+	}
+}
+
+// Test checking whether one object can structurally replace another.
+func TestIsAssignable(t *testing.T) {
+	// Define an interface-like object and a few candidates.
+	shape := goop.New()
+	shape.Set("name", "")
+	shape.Set("area", func(this goop.Object) float64 { return 0 })
+	square := goop.New()
+	square.Set("name", "square")
+	square.Set("side", 2.0)
+	square.Set("area", func(this goop.Object) float64 {
+		side := this.Get("side").(float64)
+		return side * side
+	})
+	badArea := goop.New()
+	badArea.Set("name", "bad")
+	badArea.Set("area", func(this goop.Object) int { return 0 })
+	noName := goop.New()
+	noName.Set("area", func(this goop.Object) float64 { return 0 })
+
+	// Ensure that only the compatible candidate is assignable.
+	if !goop.IsAssignable(square, shape) {
+		t.Fatalf("Expected square to be assignable to shape")
+	}
+	if goop.IsAssignable(shape, square) {
+		t.Fatalf("Did not expect shape to be assignable to square")
+	}
+	if goop.IsAssignable(badArea, shape) {
+		t.Fatalf("Did not expect a mismatched method to be assignable")
+	}
+	if goop.IsAssignable(noName, shape) {
+		t.Fatalf("Did not expect a missing member to be assignable")
+	}
+}
+
+// Test cloning an object.
+func TestClone(t *testing.T) {
+	parent := goop.New()
+	parent.Set("kind", "point")
+	orig := goop.New()
+	orig.SetSuper(parent)
+	orig.Set("x", 1)
+	orig.Set("getX", func(self goop.Object) int { return self.Get("x").(int) })
+	clone := orig.Clone()
+
+	// Ensure that the clone has the same members and lineage.
+	if result := clone.Call("getX")[0].(int); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if result := clone.Super(); len(result) != 1 || !result[0].IsEquiv(parent) {
+		t.Fatalf("Expected the clone to share its parent with the original")
+	}
+	if clone.IsEquiv(orig) {
+		t.Fatalf("Did not expect the clone to be equivalent to the original")
+	}
+
+	// Ensure that modifying the clone does not affect the original.
+	clone.Set("x", 2)
+	clone.Set("y", 3)
+	if result := orig.Get("x").(int); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if result := orig.Get("y"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	if result := clone.Call("getX")[0].(int); result != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, result)
+	}
+}
+
+// Test deeply copying an object, its nested objects, and optionally
+// its prototypes.
+func TestDeepClone(t *testing.T) {
+	// Construct an object with a parent and a nested object that
+	// refers back to the object.
+	parent := goop.New()
+	parent.Set("kind", "point")
+	orig := goop.New()
+	orig.SetSuper(parent)
+	color := goop.New()
+	color.Set("name", "red")
+	color.Set("owner", orig)
+	orig.Set("color", color)
+
+	// Ensure that nested objects are copied but prototypes are
+	// shared unless requested.
+	shallowProtos := orig.DeepClone(false)
+	cloneColor := shallowProtos.Get("color").(goop.Object)
+	if cloneColor.IsEquiv(color) {
+		t.Fatalf("Expected the nested object to be copied")
+	}
+	if owner := cloneColor.Get("owner").(goop.Object); !owner.IsEquiv(shallowProtos) {
+		t.Fatalf("Expected the nested object to refer to the clone")
+	}
+	cloneColor.Set("name", "blue")
+	if result := color.Get("name").(string); result != "red" {
+		t.Fatalf("Expected %q but saw %v", "red", result)
+	}
+	if !shallowProtos.Super()[0].IsEquiv(parent) {
+		t.Fatalf("Expected the prototype to be shared")
+	}
+	deepProtos := orig.DeepClone(true)
+	if deepProtos.Super()[0].IsEquiv(parent) {
+		t.Fatalf("Expected the prototype to be copied")
+	}
+	if result := deepProtos.Get("kind").(string); result != "point" {
+		t.Fatalf("Expected %q but saw %v", "point", result)
+	}
+}
+
+// Test merging one object's members into another.
+func TestMerge(t *testing.T) {
+	// Define a trait with an inherited member and a method.
+	base := goop.New()
+	base.Set("size", 1)
+	trait := goop.New()
+	trait.SetSuper(base)
+	trait.Set("color", "red")
+	trait.Set("describe", func(self goop.Object) string { return self.Get("color").(string) })
+
+	// Ensure that Merge without overwrite preserves existing members.
+	obj := goop.New()
+	obj.Set("color", "blue")
+	obj.Merge(trait, false)
+	if result := obj.Call("describe")[0].(string); result != "blue" {
+		t.Fatalf("Expected %q but saw %v", "blue", result)
+	}
+	if !obj.HasLocalMember("size") || len(obj.Super()) != 0 {
+		t.Fatalf("Expected inherited members to be copied locally")
+	}
+
+	// Ensure that Merge with overwrite replaces existing members
+	// and that later changes to the trait have no effect.
+	obj.Merge(trait, true)
+	trait.Set("color", "green")
+	if result := obj.Call("describe")[0].(string); result != "red" {
+		t.Fatalf("Expected %q but saw %v", "red", result)
+	}
+}
+
+// Test type-dependent dispatch with defaulted trailing arguments.
+func TestDispatchDefaults(t *testing.T) {
+	funcs := []interface{}{
+		func(self goop.Object, s string) string { return "exact " + s },
+		func(self goop.Object, s string, n, m, k int) string { return "four " + s },
+		func(self goop.Object, s string, n, m int) string { return fmt.Sprintf("three %s %d %d", s, n, m) },
+	}
+	obj := goop.New()
+	obj.Set("strict", goop.CombineFunctions(funcs...))
+	obj.Set("lenient", goop.CombineFunctionsWithDefaults(funcs...))
+
+	// Ensure that exact matches take precedence.
+	if result := obj.Call("lenient", "a")[0].(string); result != "exact a" {
+		t.Fatalf("Expected %q but saw %v", "exact a", result)
+	}
+
+	// Ensure that missing trailing arguments default to zero only
+	// when requested.
+	if result := obj.Call("lenient")[0].(string); result != "exact " {
+		t.Fatalf("Expected %q but saw %v", "exact ", result)
+	}
+	if result := obj.Call("lenient", "b", 5)[0].(string); result != "three b 5 0" {
+		t.Fatalf("Expected %q but saw %v", "three b 5 0", result)
+	}
+	if result := obj.Call("strict", "b", 5)[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	if result := obj.Call("lenient", "c", 7.5); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+}
+
 // Test the use of type-dependent dispatch (multiple methods with the
 // same name but different types).
 func TestDispatch(t *testing.T) {
@@ -234,6 +1083,531 @@ func TestDispatch(t *testing.T) {
 	}
 }
 
+// Test type-dependent dispatch to variadic functions.
+func TestDispatchVariadic(t *testing.T) {
+	joinObj := goop.New()
+	joinObj.Set("join", goop.CombineFunctions(
+		func(self goop.Object, sep string, parts ...string) string {
+			return strings.Join(parts, sep)
+		},
+		func(self goop.Object, a, b string) string { return a + "+" + b },
+		func(self goop.Object, xs ...int) int { return len(xs) }))
+	if result := joinObj.Call("join", "-", "a", "b", "c"); result[0].(string) != "a-b-c" {
+		t.Fatalf("Expected \"a-b-c\" but received %#v", result)
+	}
+	if result := joinObj.Call("join", "-"); result[0].(string) != "" {
+		t.Fatalf("Expected \"\" but received %#v", result)
+	}
+	if result := joinObj.Call("join", "a", "b"); result[0].(string) != "a+b" {
+		t.Fatalf("Expected \"a+b\" but received %#v", result)
+	}
+	if result := joinObj.Call("join", 1, 2, 3); result[0].(int) != 3 {
+		t.Fatalf("Expected 3 but received %#v", result)
+	}
+	if result := joinObj.Call("join", "-", 1); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound but received %#v", result)
+	}
+
+	// Ensure that CallSlice expands a slice into variadic arguments.
+	if result := joinObj.CallSlice("join", "/", []string{"x", "y"}); result[0].(string) != "x/y" {
+		t.Fatalf("Expected \"x/y\" but received %#v", result)
+	}
+	if result := joinObj.CallSlice("join", []int{4, 5, 6, 7}); result[0].(int) != 4 {
+		t.Fatalf("Expected 4 but received %#v", result)
+	}
+}
+
+// Test modifying the functions underlying a MetaFunction.
+func TestMetaFunctionAddRemove(t *testing.T) {
+	describe := goop.CombineFunctions(
+		func(self goop.Object, x int) string { return "int" })
+	obj := goop.New()
+	obj.Set("describe", describe)
+	if result := obj.Call("describe", "abc")[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+
+	// Ensure that added functions are dispatched to.
+	describe.Add(func(self goop.Object, s string) string { return "string" })
+	if result := obj.Call("describe", "abc")[0].(string); result != "string" {
+		t.Fatalf("Expected %q but saw %v", "string", result)
+	}
+	expected := []string{"func(goop.Object, int) string", "func(goop.Object, string) string"}
+	if sigs := describe.Signatures(); fmt.Sprint(sigs) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v but saw %v", expected, sigs)
+	}
+
+	// Ensure that removed functions are no longer dispatched to.
+	if !describe.Remove(expected[0]) || describe.Remove(expected[0]) {
+		t.Fatalf("Expected %q to be removed exactly once", expected[0])
+	}
+	if result := obj.Call("describe", 5)[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+}
+
+// Test that a hand-written MetaFunction is invoked only when called.
+func TestUserMetaFunction(t *testing.T) {
+	var calls [][]interface{}
+	sum := goop.MetaFunction(func(args ...interface{}) []interface{} {
+		calls = append(calls, args)
+		return []interface{}{len(args)}
+	})
+	obj := goop.New()
+	obj.Set("sum", sum)
+	if sigs := sum.Signatures(); sigs != nil {
+		t.Fatalf("Expected %v but saw %v", nil, sigs)
+	}
+	obj.Call("sum", 1, 2)
+	obj.CallErr("sum", 3)
+	obj.CallContext(context.Background(), "sum")
+	if len(calls) != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, calls)
+	}
+	for i, n := range []int{3, 2, 1} {
+		if len(calls[i]) != n {
+			t.Fatalf("Expected %d but saw %v", n, calls[i])
+		}
+		if _, ok := calls[i][0].(goop.Object); !ok {
+			t.Fatalf("Expected an Object but saw %v", calls[i][0])
+		}
+	}
+}
+
+// Test building up a method's overloads incrementally.
+func TestOverload(t *testing.T) {
+	parent := goop.New()
+	parent.Overload("describe", func(self goop.Object, x int) string { return "int" })
+	child := goop.New()
+	child.SetSuper(parent)
+	child.Overload("describe", func(self goop.Object, s string) string { return "string" })
+	child.Overload("describe", func(self goop.Object, s string) string { return "text" })
+
+	// Ensure that the child has all overloads but the parent is
+	// unaffected.
+	if result := child.Call("describe", 5)[0].(string); result != "int" {
+		t.Fatalf("Expected %q but saw %v", "int", result)
+	}
+	if result := child.Call("describe", "abc")[0].(string); result != "text" {
+		t.Fatalf("Expected %q but saw %v", "text", result)
+	}
+	if _, err := parent.CallErr("describe", "abc"); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test type-dependent dispatch with numeric promotion.
+func TestDispatchPromotion(t *testing.T) {
+	obj := goop.New()
+	obj.Set("scale", goop.CombineFunctionsWithPromotion(
+		func(self goop.Object, x int64) string { return "int64" },
+		func(self goop.Object, x float64) string { return "float64" },
+		func(self goop.Object, x int64, y float64) string { return "int64, float64" },
+		func(self goop.Object, x float64, y int64) string { return "float64, int64" }))
+
+	// Ensure that arguments are promoted to the closest type.
+	for _, c := range []struct {
+		args     []interface{}
+		expected string
+	}{
+		{[]interface{}{int8(1)}, "int64"},
+		{[]interface{}{2}, "int64"},
+		{[]interface{}{uint32(3)}, "int64"},
+		{[]interface{}{float32(4)}, "float64"},
+		{[]interface{}{5, 6.0}, "int64, float64"},
+	} {
+		if result, err := obj.Call1("scale", c.args...); err != nil || result != c.expected {
+			t.Fatalf("Expected (%q, nil) but saw (%v, %v)", c.expected, result, err)
+		}
+	}
+
+	// Ensure that ties and lossy conversions are rejected.
+	if _, err := obj.CallErr("scale", 5, 6); !errors.Is(err, goop.ErrAmbiguous) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrAmbiguous, err)
+	}
+	if _, err := obj.CallErr("scale", 1.5, 2.5); !errors.Is(err, goop.ErrBadArguments) || errors.Is(err, goop.ErrAmbiguous) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test type-dependent dispatch to functions whose parameters are
+// interfaces.
+func TestDispatchAssignable(t *testing.T) {
+	writerObj := goop.New()
+	writerObj.Set("write", goop.CombineFunctions(
+		func(self goop.Object, w io.Writer, s string) int {
+			n, _ := io.WriteString(w, s)
+			return n
+		},
+		func(self goop.Object, w io.Writer, x interface{}) int {
+			n, _ := fmt.Fprint(w, x)
+			return -n
+		}))
+	var buf bytes.Buffer
+	if result := writerObj.Call("write", &buf, "Hello"); result[0].(int) != 5 {
+		t.Fatalf("Expected 5 but received %#v", result)
+	}
+	if result := writerObj.Call("write", &buf, 123); result[0].(int) != -3 {
+		t.Fatalf("Expected -3 but received %#v", result)
+	}
+	if buf.String() != "Hello123" {
+		t.Fatalf("Expected \"Hello123\" but saw %q", buf.String())
+	}
+	if result := writerObj.Call("write", buf, "Hello"); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound but received %#v", result)
+	}
+}
+
+// A Thing is used to test passing nil pointers to methods.
+type Thing struct {
+	Name string
+}
+
+// Test passing nil arguments to methods.
+func TestNilArguments(t *testing.T) {
+	// Ensure that Call accepts nil arguments.
+	obj := goop.New()
+	obj.Set("describe", func(self goop.Object, p *Thing) string {
+		if p == nil {
+			return "nothing"
+		}
+		return p.Name
+	})
+	if result := obj.Call("describe", nil); result[0].(string) != "nothing" {
+		t.Fatalf("Expected \"nothing\" but received %#v", result)
+	}
+
+	// Ensure that type-dependent dispatch accepts nil arguments.
+	obj.Set("describe", goop.CombineFunctions(
+		func(self goop.Object, n int) string { return "int" },
+		func(self goop.Object, p *Thing) string { return "thing" },
+		func(self goop.Object, n int, m map[string]int) string { return "map" }))
+	if result := obj.Call("describe", nil); result[0].(string) != "thing" {
+		t.Fatalf("Expected \"thing\" but received %#v", result)
+	}
+	if result := obj.Call("describe", 1, nil); result[0].(string) != "map" {
+		t.Fatalf("Expected \"map\" but received %#v", result)
+	}
+	if result := obj.Call("describe", nil, nil); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound but received %#v", result)
+	}
+}
+
+// Test distinguishing failed calls from methods that return errors.
+func TestCallErr(t *testing.T) {
+	obj := goop.New()
+	obj.Set("notFound", func(self goop.Object) error { return goop.ErrNotFound })
+	obj.Set("negate", goop.CombineFunctions(
+		func(self goop.Object, x int) int { return -x }))
+
+	// Ensure that a method returning ErrNotFound is not a failure.
+	result, err := obj.CallErr("notFound")
+	if err != nil || len(result) != 1 || result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ([ErrNotFound], nil) but saw (%#v, %v)", result, err)
+	}
+
+	// Ensure that missing methods and mismatched arguments are
+	// reported as errors.
+	if _, err = obj.CallErr("bogus"); !errors.Is(err, goop.ErrNotFound) || !strings.Contains(err.Error(), "bogus") {
+		t.Fatalf("Expected an error wrapping ErrNotFound but saw %v", err)
+	}
+	if _, err = obj.CallErr("negate", "abc"); !errors.Is(err, goop.ErrBadArguments) || !strings.Contains(err.Error(), "string") {
+		t.Fatalf("Expected an error wrapping ErrBadArguments but saw %v", err)
+	}
+	if result, err = obj.CallErr("negate", 5); err != nil || result[0].(int) != -5 {
+		t.Fatalf("Expected ([-5], nil) but saw (%#v, %v)", result, err)
+	}
+}
+
+// Test identifying the missing member from a NotFoundError.
+func TestNotFoundError(t *testing.T) {
+	obj := goop.New()
+	_, err := obj.CallErr("missingMethod")
+	var nfErr *goop.NotFoundError
+	if !errors.As(err, &nfErr) || nfErr.Member != "missingMethod" {
+		t.Fatalf("Expected a NotFoundError for %q but saw %v", "missingMethod", err)
+	}
+	if !errors.Is(err, goop.ErrNotFound) {
+		t.Fatalf("Expected %v to be %v", err, goop.ErrNotFound)
+	}
+	_, err = goop.GetAs[int](obj, "missingMember")
+	if !errors.As(err, &nfErr) || nfErr.Member != "missingMember" {
+		t.Fatalf("Expected a NotFoundError for %q but saw %v", "missingMember", err)
+	}
+	if errors.Is(err, goop.ErrNoSuchMethod) {
+		t.Fatalf("Expected %v not to be %v", err, goop.ErrNoSuchMethod)
+	}
+}
+
+// Test distinguishing missing methods from methods that return errors.
+func TestNoSuchMethod(t *testing.T) {
+	obj := goop.New()
+	obj.Set("data", 5)
+	obj.Set("fail", func(self goop.Object) error { return goop.ErrNotFound })
+	if _, err := obj.CallErr("bogus"); !errors.Is(err, goop.ErrNoSuchMethod) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoSuchMethod, err)
+	}
+	if _, err := obj.CallErr("data"); !errors.Is(err, goop.ErrNoSuchMethod) || !strings.Contains(err.Error(), "int") {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNoSuchMethod, err)
+	}
+	if result, err := obj.CallErr("fail"); err != nil || result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ([%v], nil) but saw (%v, %v)", goop.ErrNotFound, result, err)
+	}
+}
+
+// Test recovering from a method that panics.
+func TestPanicError(t *testing.T) {
+	obj := goop.New()
+	obj.Set("index", func(self goop.Object, s []int, i int) int { return s[i] })
+	obj.Set("frozen", func(self goop.Object) { panic(goop.ErrFrozen) })
+	_, err := obj.CallErr("index", []int{1, 2}, 5)
+	var panicErr *goop.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Method != "index" || len(panicErr.Args) != 2 {
+		t.Fatalf("Expected a PanicError but saw %v", err)
+	}
+	if !strings.Contains(err.Error(), "[]int") {
+		t.Fatalf("Expected %q to name the argument types", err.Error())
+	}
+	if _, err := obj.CallErr("frozen"); !errors.Is(err, goop.ErrFrozen) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, err)
+	}
+
+	// Ensure that Call panics with the PanicError.
+	recovered := func(f func()) (r interface{}) {
+		defer func() { r = recover() }()
+		f()
+		return nil
+	}
+	r := recovered(func() { obj.Call("index", []int{1, 2}, 5) })
+	if panicErr, ok := r.(*goop.PanicError); !ok || !panicErr.Object.IsEquiv(obj) || panicErr.Method != "index" {
+		t.Fatalf("Expected a PanicError but saw %v", r)
+	}
+	child := goop.New()
+	child.SetSuper(obj)
+	r = recovered(func() { child.CallSuper("frozen") })
+	if err, ok := r.(error); !ok || !errors.Is(err, goop.ErrFrozen) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, r)
+	}
+
+	// Ensure that arguments the method does not accept make Call
+	// panic with an ArgumentError and CallErr return it.
+	r = recovered(func() { obj.Call("index", "str") })
+	if argErr, ok := r.(*goop.ArgumentError); !ok || argErr.Method != "index" {
+		t.Fatalf("Expected an ArgumentError but saw %v", r)
+	}
+	if _, err := obj.CallErr("index", "str"); !errors.As(err, new(*goop.ArgumentError)) {
+		t.Fatalf("Expected an ArgumentError but saw %v", err)
+	}
+
+	// Ensure that a panic in a method invoked through an adapter is
+	// reported as a PanicError.
+	obj.Set("Len", func(self goop.Object) int { panic("no length") })
+	obj.Set("Less", func(self goop.Object, i, j int) bool { return i < j })
+	obj.Set("Swap", func(self goop.Object, i, j int) {})
+	sorter, err := goop.Implement[sort.Interface](obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = recovered(func() { sorter.Len() })
+	if panicErr, ok := r.(*goop.PanicError); !ok || panicErr.Method != "Len" {
+		t.Fatalf("Expected a PanicError but saw %v", r)
+	}
+}
+
+// Test forwarding calls to missing methods.
+func TestMethodMissing(t *testing.T) {
+	target := goop.New()
+	target.Set("double", func(self goop.Object, x int) int { return 2 * x })
+	proxy := goop.New()
+	proxy.Set(goop.MethodMissing, func(self goop.Object, name string, args ...interface{}) []interface{} {
+		return target.Call(name, args...)
+	})
+	if result := proxy.Call("double", 21)[0].(int); result != 42 {
+		t.Fatalf("Expected %d but saw %v", 42, result)
+	}
+	if result, err := proxy.CallErr("double", 4); err != nil || result[0].(int) != 8 {
+		t.Fatalf("Expected ([%d], nil) but saw (%v, %v)", 8, result, err)
+	}
+	if result := proxy.Call("bogus")[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+}
+
+// Test binding methods to an object.
+func TestBind(t *testing.T) {
+	obj := goop.New()
+	obj.Set("scale", 10)
+	obj.Set("affine", func(self goop.Object, a, b int) int { return self.Get("scale").(int)*a + b })
+
+	// Ensure that bound methods are invoked on the object.
+	affine, err := obj.Bind("affine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := affine(2, 3)[0].(int); result != 23 {
+		t.Fatalf("Expected %d but saw %v", 23, result)
+	}
+	affine4, err := obj.BindPartial("affine", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := affine4(5)[0].(int); result != 45 {
+		t.Fatalf("Expected %d but saw %v", 45, result)
+	}
+
+	// Ensure that bound methods reflect redefinitions.
+	obj.Set("affine", func(self goop.Object, a, b int) int { return a - b })
+	if result := affine4(5)[0].(int); result != -1 {
+		t.Fatalf("Expected %d but saw %v", -1, result)
+	}
+	if _, err = obj.Bind("bogus"); !errors.Is(err, goop.ErrNotFound) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, err)
+	}
+
+	// Ensure that the package-level Bind composes with dispatch.
+	obj.Set("describe", goop.CombineFunctions(
+		func(self goop.Object, a int) string { return "one" },
+		func(self goop.Object, a, b int) string { return "two" }))
+	describe := goop.Bind(obj, "describe", 1)
+	if result := describe()[0].(string); result != "one" {
+		t.Fatalf("Expected %q but saw %v", "one", result)
+	}
+	if result := describe(2)[0].(string); result != "two" {
+		t.Fatalf("Expected %q but saw %v", "two", result)
+	}
+}
+
+// Test extracting a method as a callback.
+func TestMethod(t *testing.T) {
+	obj := goop.New()
+	obj.Set("scale", 3)
+	obj.Set("times", func(self goop.Object, x int) int { return self.Get("scale").(int) * x })
+	times := obj.Method("times")
+
+	// Ensure that the method captures its current definition but
+	// still sees changes to the object's data.
+	obj.Set("times", func(self goop.Object, x int) int { return 0 })
+	obj.Set("scale", 4)
+	if result := times(5)[0].(int); result != 20 {
+		t.Fatalf("Expected %d but saw %v", 20, result)
+	}
+	if result := obj.Method("bogus")()[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+}
+
+// Test calling methods that return a single value.
+func TestCall1(t *testing.T) {
+	obj := goop.New()
+	obj.Set("square", func(self goop.Object, x int) int { return x * x })
+	obj.Set("nothing", func(self goop.Object) {})
+	obj.Set("pair", func(self goop.Object) (int, int) { return 1, 2 })
+	if result, err := obj.Call1("square", 7); err != nil || result.(int) != 49 {
+		t.Fatalf("Expected (%d, nil) but saw (%v, %v)", 49, result, err)
+	}
+	if result, err := obj.Call1("nothing"); err != nil || result != nil {
+		t.Fatalf("Expected (nil, nil) but saw (%v, %v)", result, err)
+	}
+	if _, err := obj.Call1("pair"); !errors.Is(err, goop.ErrMultipleResults) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrMultipleResults, err)
+	}
+	if _, err := obj.Call1("bogus"); !errors.Is(err, goop.ErrNotFound) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, err)
+	}
+}
+
+// Test that calling a method with the wrong number or types of
+// arguments produces a descriptive ArgumentError.
+func TestArgumentError(t *testing.T) {
+	obj := goop.New()
+	obj.Set("add", func(self goop.Object, x, y int) int { return x + y })
+
+	// Ensure that CallErr reports too few arguments and arguments
+	// of the wrong type.
+	_, err := obj.CallErr("add", 1)
+	var argErr *goop.ArgumentError
+	if !errors.As(err, &argErr) || !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected an ArgumentError but saw %v", err)
+	}
+	if argErr.Method != "add" || len(argErr.Actual) != 2 {
+		t.Fatalf("Expected method %q with %d arguments but saw %q with %d", "add", 2, argErr.Method, len(argErr.Actual))
+	}
+	_, err = obj.CallErr("add", 1, "two")
+	if !errors.As(err, &argErr) {
+		t.Fatalf("Expected an ArgumentError but saw %v", err)
+	}
+	for _, s := range []string{`"add"`, "func(goop.Object, int, int) int", "(goop.Object, int, string)"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("Expected %q to contain %q", err.Error(), s)
+		}
+	}
+
+	// Ensure that Call panics with an ArgumentError.
+	defer func() {
+		if r, ok := recover().(*goop.ArgumentError); !ok || r.Method != "add" {
+			t.Fatalf("Expected an ArgumentError but saw %v", r)
+		}
+	}()
+	obj.Call("add", 1)
+}
+
+// Celsius and Fahrenheit are distinct types with the same underlying
+// kind.
+type Celsius float64
+type Fahrenheit float64
+
+// Test type-dependent dispatch on named types that share a kind.
+func TestDispatchNamedTypes(t *testing.T) {
+	tempObj := goop.New()
+	tempObj.Set("toKelvin", goop.CombineFunctions(
+		func(self goop.Object, c Celsius) float64 { return float64(c) + 273.15 },
+		func(self goop.Object, f Fahrenheit) float64 { return (float64(f)-32)*5/9 + 273.15 },
+		func(self goop.Object, cs []Celsius) int { return len(cs) },
+		func(self goop.Object, fs []Fahrenheit) int { return -len(fs) }))
+	if result := tempObj.Call("toKelvin", Celsius(100)); result[0].(float64) != 373.15 {
+		t.Fatalf("Expected 373.15 but received %#v", result)
+	}
+	if result := tempObj.Call("toKelvin", Fahrenheit(212)); result[0].(float64) != 373.15 {
+		t.Fatalf("Expected 373.15 but received %#v", result)
+	}
+	if result := tempObj.Call("toKelvin", []Celsius{1, 2}); result[0].(int) != 2 {
+		t.Fatalf("Expected 2 but received %#v", result)
+	}
+	if result := tempObj.Call("toKelvin", []Fahrenheit{1, 2}); result[0].(int) != -2 {
+		t.Fatalf("Expected -2 but received %#v", result)
+	}
+	if result := tempObj.Call("toKelvin", 100.0); result[0] != goop.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound but received %#v", result)
+	}
+}
+
+// Test type-dependent dispatch on distinct types with the same name.
+func TestDispatchSameName(t *testing.T) {
+	// Declare two types that are both named "Point".
+	var p1, p2 interface{}
+	var f1, f2 interface{}
+	{
+		type Point struct{ X int }
+		p1 = Point{1}
+		f1 = func(self goop.Object, p Point) int { return p.X }
+	}
+	{
+		type Point struct{ X int }
+		p2 = Point{2}
+		f2 = func(self goop.Object, p Point) int { return -p.X }
+	}
+
+	// Ensure that each type dispatches to its own function.
+	obj := goop.New()
+	obj.Set("get", goop.CombineFunctions(f1, f2))
+	if result := obj.Call("get", p1)[0].(int); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if result := obj.Call("get", p2)[0].(int); result != -2 {
+		t.Fatalf("Expected %d but saw %v", -2, result)
+	}
+}
+
 // The following is used by nativeFNV1.  We hope that making it
 // exportable will prevent the compiler from optimizing it away.
 var NativeHashVal uint64 = 14695981039346656037
@@ -324,3 +1698,53 @@ func BenchmarkEvenMoreGoopFNV1(b *testing.B) {
 		fnv1Obj.Call("fnv1")
 	}
 }
+
+// Define a set of functions to which dispatch succeeds only via an
+// assignability check (a *bytes.Buffer argument to an io.Writer
+// parameter).
+var writerFuncs = []interface{}{
+	func(this goop.Object, x int) {},
+	func(this goop.Object, s string) {},
+	func(this goop.Object, w io.Writer) {},
+}
+
+// Measure the speed of repeatedly dispatching to a function that
+// accepts its arguments only by assignability.  After the first call,
+// the resolved function is retrieved from the MetaFunction's cache.
+func BenchmarkDispatchCached(b *testing.B) {
+	b.StopTimer()
+	obj := goop.New()
+	obj.Set("write", goop.CombineFunctions(writerFuncs...))
+	var buf bytes.Buffer
+	b.StartTimer()
+	for i := b.N; i > 0; i-- {
+		obj.Call("write", &buf)
+	}
+}
+
+// Measure the speed of dispatching to a function that accepts its
+// arguments only by assignability when the MetaFunction is new and
+// therefore has nothing cached.
+func BenchmarkDispatchUncached(b *testing.B) {
+	b.StopTimer()
+	obj := goop.New()
+	var buf bytes.Buffer
+	b.StartTimer()
+	for i := b.N; i > 0; i-- {
+		obj.Set("write", goop.CombineFunctions(writerFuncs...))
+		obj.Call("write", &buf)
+	}
+}
+
+// Measure the speed of creating objects that each have the same few
+// members.
+func BenchmarkNewPoints(b *testing.B) {
+	point := func(this goop.Object, x, y, z float64) {
+		this.Set("x", x)
+		this.Set("y", y)
+		this.Set("z", z)
+	}
+	for i := b.N; i > 0; i-- {
+		goop.New(point, 1.0, 2.0, 3.0)
+	}
+}
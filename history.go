@@ -0,0 +1,148 @@
+// This file implements undo and redo of changes to an object.
+
+package goop
+
+// A history records the states an object can return to via Undo and
+// Redo.
+type history struct {
+	depth int         // Maximum number of states to retain for Undo
+	undo  []*Snapshot // States preceding each recorded change, oldest first
+	redo  []*Snapshot // States undone by Undo, most recently undone last
+}
+
+// EnableHistory begins recording changes to the object so they can be
+// reverted with Undo and reapplied with Redo.  Every change made by
+// Set, Unset, Add, SetSuper, AddSuper, RemoveSuper, DefineProperty, or
+// DefineComputed is recorded, as are changes made by functions built
+// on them.  At most depth changes are retained; older changes are
+// forgotten.  Recording relies on snapshots, so as with Restore,
+// modifications made within a member's value (e.g., to a slice's
+// elements) are not recorded.  Calling EnableHistory on an object
+// that already records history changes the depth and discards any
+// changes beyond it.
+func (obj *Object) EnableHistory(depth int) {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if impl.history == nil {
+		impl.history = &history{}
+	}
+	impl.history.depth = depth
+	impl.history.trim()
+}
+
+// DisableHistory stops recording changes to the object and discards
+// any recorded changes.
+func (obj *Object) DisableHistory() {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	impl.history = nil
+	impl.lock.Unlock()
+}
+
+// Undo reverts the object's most recent recorded change and returns
+// true, or returns false if there is no change to revert (including
+// when history is not enabled), if the object is frozen, or if
+// reverting the change would restore prototypes that introduce a
+// cycle.  A change that cannot be reverted remains recorded.  Watchers
+// are not notified.
+func (obj *Object) Undo() bool {
+	return obj.travel(func(h *history) (*[]*Snapshot, *[]*Snapshot) {
+		return &h.undo, &h.redo
+	})
+}
+
+// Redo reapplies the change most recently reverted by Undo and returns
+// true, or returns false if there is no such change, if the object is
+// frozen, or if reapplying the change would restore prototypes that
+// introduce a cycle.  Any newly recorded change discards the changes
+// available to Redo.  Watchers are not notified.
+func (obj *Object) Redo() bool {
+	return obj.travel(func(h *history) (*[]*Snapshot, *[]*Snapshot) {
+		return &h.redo, &h.undo
+	})
+}
+
+// travel restores the state on top of one of the object's history
+// stacks, selected by stacks as from, and pushes the object's current
+// state onto the other.  It returns false, leaving both stacks
+// unchanged, if there is no state to restore or the state cannot be
+// restored.  As with Restore, the state's prototypes are checked for
+// cycles without holding the object's lock, so travel retries if the
+// history changes in the meantime.
+func (obj *Object) travel(stacks func(h *history) (from, to *[]*Snapshot)) bool {
+	impl := obj.Implementation
+	for {
+		impl.lock.RLock()
+		h := impl.history
+		var snap *Snapshot
+		if h != nil && !impl.frozen {
+			if from, _ := stacks(h); len(*from) > 0 {
+				snap = (*from)[len(*from)-1]
+			}
+		}
+		impl.lock.RUnlock()
+		if snap == nil {
+			return false
+		}
+		if snap.withProtos && obj.introducesCycle(snap.prototypes) {
+			return false
+		}
+
+		impl.lock.Lock()
+		from, to := stacks(h)
+		if impl.history != h || len(*from) == 0 || (*from)[len(*from)-1] != snap {
+			impl.lock.Unlock()
+			continue
+		}
+		current := impl.capture(true)
+		if impl.restore(snap) != nil {
+			impl.lock.Unlock()
+			return false
+		}
+		*from = (*from)[:len(*from)-1]
+		*to = append(*to, current)
+		h.trim()
+		impl.lock.Unlock()
+		return true
+	}
+}
+
+// recordHistory records the object's current state, if history is
+// enabled, in anticipation of a change.  The caller must hold the
+// object's lock.
+func (impl *internal) recordHistory() {
+	impl.commitHistory(impl.prepareHistory())
+}
+
+// prepareHistory is like recordHistory but for a change that may fail.
+// It returns the object's current state, if history is enabled, to be
+// passed to commitHistory once the change succeeds, or nil.  The
+// caller must hold the object's lock.
+func (impl *internal) prepareHistory() *Snapshot {
+	if impl.history == nil {
+		return nil
+	}
+	return impl.capture(true)
+}
+
+// commitHistory records a state returned by prepareHistory and
+// discards the changes available to Redo.  The caller must hold the
+// object's lock.
+func (impl *internal) commitHistory(snap *Snapshot) {
+	h := impl.history
+	if h == nil || snap == nil {
+		return
+	}
+	h.undo = append(h.undo, snap)
+	h.redo = nil
+	h.trim()
+}
+
+// trim discards the oldest recorded states in excess of the history's
+// depth.
+func (h *history) trim() {
+	if excess := len(h.undo) - h.depth; excess > 0 {
+		h.undo = append([]*Snapshot(nil), h.undo[excess:]...)
+	}
+}
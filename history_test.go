@@ -0,0 +1,97 @@
+// This file tests undoing and redoing changes to objects.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test undoing and redoing member and prototype changes.
+func TestUndoRedo(t *testing.T) {
+	// Record a sequence of changes.
+	parent := goop.New()
+	obj := goop.New()
+	obj.EnableHistory(3)
+	obj.Set("x", 1)
+	obj.Set("x", 2)
+	obj.SetSuper(parent)
+	obj.Unset("x")
+
+	// Ensure that changes are undone in reverse order, up to the
+	// history's depth.
+	if !obj.Undo() || obj.Get("x").(int) != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, obj.Get("x"))
+	}
+	if !obj.Undo() || len(obj.Super()) != 0 {
+		t.Fatalf("Expected %d prototypes but saw %d", 0, len(obj.Super()))
+	}
+	if !obj.Undo() || obj.Get("x").(int) != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, obj.Get("x"))
+	}
+	if obj.Undo() {
+		t.Fatalf("Expected no more changes to undo")
+	}
+
+	// Ensure that Redo reapplies undone changes until a new change
+	// is made.
+	if !obj.Redo() || obj.Get("x").(int) != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, obj.Get("x"))
+	}
+	obj.Set("y", 3)
+	if obj.Redo() {
+		t.Fatalf("Expected no changes to redo")
+	}
+
+	// Ensure that disabling history discards it.
+	obj.DisableHistory()
+	if obj.Undo() {
+		t.Fatalf("Expected no changes to undo")
+	}
+}
+
+// Test that Undo refuses to restore prototypes that would introduce a
+// cycle.
+func TestUndoCycle(t *testing.T) {
+	a := goop.New()
+	b := goop.New()
+	a.EnableHistory(10)
+	a.SetSuper(b)
+	a.SetSuper()
+	b.SetSuper(a)
+	if a.Undo() {
+		t.Fatalf("Expected %v but saw %v", false, true)
+	}
+	if result := a.Get("x"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+
+	// Ensure that the change can be undone once the cycle is gone.
+	b.SetSuper()
+	if !a.Undo() {
+		t.Fatalf("Expected %v but saw %v", true, false)
+	}
+	if super := a.Super(); len(super) != 1 || !super[0].IsEquiv(b) {
+		t.Fatalf("Expected %v but saw %v", []goop.Object{b}, super)
+	}
+}
+
+// Test that a failed change does not discard the changes available to
+// Redo.
+func TestRedoAfterFailedSet(t *testing.T) {
+	obj := goop.Wrap(&Account{Owner: "Bob", Balance: 50})
+	obj.EnableHistory(5)
+	obj.Set("note", "first")
+	if !obj.Undo() || obj.HasMember("note") {
+		t.Fatalf("Expected the note to be undone")
+	}
+	if err := obj.TrySet("Balance", "lots"); err == nil {
+		t.Fatalf("Expected an error but saw %v", err)
+	}
+	if !obj.Redo() || obj.Get("note") != "first" {
+		t.Fatalf("Expected %q but saw %v", "first", obj.Get("note"))
+	}
+	if !obj.Undo() || obj.Undo() {
+		t.Fatalf("Expected exactly one change to undo")
+	}
+}
@@ -0,0 +1,170 @@
+// This file implements method hooks, which run before, after, or
+// around a method each time it is invoked.
+
+package goop
+
+import "fmt"
+import "sync/atomic"
+
+// A HookKind specifies when a hook registered with AddHook runs
+// relative to the method it is attached to.
+type HookKind int
+
+// These are the kinds of hook accepted by AddHook.
+const (
+	Before HookKind = iota // Run before the method and possibly replace its arguments
+	After                  // Run after the method and possibly replace its results
+	Around                 // Run in place of the method and decide whether and how to invoke it
+)
+
+// String returns the name of a HookKind.
+func (kind HookKind) String() string {
+	switch kind {
+	case Before:
+		return "Before"
+	case After:
+		return "After"
+	case Around:
+		return "Around"
+	default:
+		return fmt.Sprintf("HookKind(%d)", int(kind))
+	}
+}
+
+// An advice is a hook in its most general form: a function that is
+// given a method's arguments and a function that continues the call.
+type advice func(this Object, arguments []interface{}, proceed func([]interface{}) []interface{}) []interface{}
+
+// A hook represents a function registered with AddHook.
+type hook struct {
+	around advice // Function to run around the method
+}
+
+// numHooks counts the hooks currently registered on all objects.  It
+// lets calls skip searching for hooks when no object has any.
+var numHooks int64
+
+// AddHook registers a function to run whenever the named method is
+// invoked on the object or on any of its descendants by Call, CallErr,
+// or a function built on them.  The hook applies regardless of whether
+// the method is defined by the object itself or inherited from a
+// prototype.  The function's type depends on the kind of hook:
+//
+//	Before: func(this goop.Object, args []interface{}) []interface{}
+//	After:  func(this goop.Object, args, results []interface{}) []interface{}
+//	Around: func(this goop.Object, args []interface{},
+//	             proceed func(args []interface{}) []interface{}) []interface{}
+//
+// A Before hook returns the arguments to pass to the method, and an
+// After hook returns the results to pass to the caller; either may
+// simply return what it was given.  An Around hook invokes proceed to
+// continue the call and returns the call's results.  It may modify the
+// arguments or results or may short-circuit the method entirely by
+// not invoking proceed.  Hooks on an object run before (that is,
+// outside of) hooks on its prototypes, and hooks on the same object
+// run in the order in which they were registered.  AddHook panics if
+// the function's type does not match the kind of hook.  It returns a
+// function that unregisters the hook.
+func (obj *Object) AddHook(kind HookKind, methodName string, function interface{}) (cancel func()) {
+	h := &hook{around: newAdvice(kind, function)}
+	impl := obj.Implementation
+	impl.lock.Lock()
+	if impl.hooks == nil {
+		impl.hooks = make(map[string][]*hook)
+	}
+	impl.hooks[methodName] = appendItem(impl.hooks[methodName], h)
+	impl.lock.Unlock()
+	atomic.AddInt64(&numHooks, 1)
+	return func() {
+		impl.lock.Lock()
+		removed := removeItem(impl.hooks, methodName, h)
+		impl.lock.Unlock()
+		if removed {
+			atomic.AddInt64(&numHooks, -1)
+		}
+	}
+}
+
+// newAdvice converts a function of the form required by a given kind
+// of hook to an advice.
+func newAdvice(kind HookKind, function interface{}) advice {
+	switch fn := function.(type) {
+	case func(Object, []interface{}) []interface{}:
+		if kind == Before {
+			return func(this Object, arguments []interface{}, proceed func([]interface{}) []interface{}) []interface{} {
+				return proceed(fn(this, arguments))
+			}
+		}
+	case func(Object, []interface{}, []interface{}) []interface{}:
+		if kind == After {
+			return func(this Object, arguments []interface{}, proceed func([]interface{}) []interface{}) []interface{} {
+				return fn(this, arguments, proceed(arguments))
+			}
+		}
+	case func(Object, []interface{}, func([]interface{}) []interface{}) []interface{}:
+		if kind == Around {
+			return fn
+		}
+	}
+	panic(fmt.Errorf("A %v hook cannot be a %T", kind, function))
+}
+
+// appendItem returns a new slice containing a list followed by one
+// more item.  Lists of hooks, watchers, and event handlers are never
+// modified in place, only replaced, by appendItem and removeItem, so
+// they can be safely traversed after the object's lock is released.
+func appendItem[T any](list []T, item T) []T {
+	result := make([]T, 0, len(list)+1)
+	result = append(result, list...)
+	return append(result, item)
+}
+
+// removeItem removes an item from the list a map associates with a key
+// and reports whether the item was found.  The caller must hold the
+// lock of the object that owns the map.  The key is deleted when its
+// last item is removed.
+func removeItem[K, T comparable](lists map[K][]T, key K, item T) bool {
+	list := lists[key]
+	for i, other := range list {
+		if other != item {
+			continue
+		}
+		if len(list) == 1 {
+			delete(lists, key)
+			return true
+		}
+		result := make([]T, 0, len(list)-1)
+		result = append(result, list[:i]...)
+		lists[key] = append(result, list[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// hooksFor returns all hooks registered on the named method by the
+// object and its ancestors, outermost first.
+func (obj *Object) hooksFor(methodName string) []*hook {
+	if atomic.LoadInt64(&numHooks) == 0 {
+		return nil
+	}
+	var hooks []*hook
+	for _, ancestor := range obj.Ancestors(true) {
+		impl := ancestor.Implementation
+		impl.lock.RLock()
+		hooks = append(hooks, impl.hooks[methodName]...)
+		impl.lock.RUnlock()
+	}
+	return hooks
+}
+
+// runHooks invokes a method through a list of hooks, each of which
+// wraps those that follow it.  The final hook wraps invoke, which
+// calls the method itself.
+func runHooks(this Object, hooks []*hook, arguments []interface{}, invoke func([]interface{}) []interface{}) []interface{} {
+	if len(hooks) == 0 {
+		return invoke(arguments)
+	}
+	return hooks[0].around(this, arguments, func(arguments []interface{}) []interface{} {
+		return runHooks(this, hooks[1:], arguments, invoke)
+	})
+}
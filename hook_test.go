@@ -0,0 +1,82 @@
+// This file tests hooks that run before, after, or around methods.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test modifying arguments and results with Before and After hooks.
+func TestHookBeforeAfter(t *testing.T) {
+	// Define a prototype with a method and hook the method in a
+	// descendant.
+	proto := goop.New()
+	proto.Set("double", func(this goop.Object, x int) int { return x * 2 })
+	obj := goop.New()
+	obj.SetSuper(proto)
+	cancelBefore := obj.AddHook(goop.Before, "double", func(this goop.Object, args []interface{}) []interface{} {
+		return []interface{}{args[0].(int) + 1}
+	})
+	obj.AddHook(goop.After, "double", func(this goop.Object, args, results []interface{}) []interface{} {
+		return []interface{}{results[0].(int) + 100}
+	})
+
+	// Ensure that both hooks run on the inherited method.
+	if result := obj.Call("double", 5)[0].(int); result != 112 {
+		t.Fatalf("Expected %d but saw %d", 112, result)
+	}
+	if results, err := obj.CallErr("double", 5); err != nil || results[0].(int) != 112 {
+		t.Fatalf("Expected %d but saw %v (%v)", 112, results, err)
+	}
+
+	// Ensure that the prototype is unaffected and that canceling a
+	// hook removes it.
+	if result := proto.Call("double", 5)[0].(int); result != 10 {
+		t.Fatalf("Expected %d but saw %d", 10, result)
+	}
+	cancelBefore()
+	if result := obj.Call("double", 5)[0].(int); result != 110 {
+		t.Fatalf("Expected %d but saw %d", 110, result)
+	}
+}
+
+// Test short-circuiting a method with an Around hook.
+func TestHookAround(t *testing.T) {
+	// Cache the results of a method in a prototype so the hook
+	// applies to all descendants.
+	calls := 0
+	proto := goop.New()
+	proto.Set("square", func(this goop.Object, x int) int {
+		calls++
+		return x * x
+	})
+	cache := make(map[int][]interface{})
+	proto.AddHook(goop.Around, "square", func(this goop.Object, args []interface{}, proceed func([]interface{}) []interface{}) []interface{} {
+		key := args[0].(int)
+		if results, ok := cache[key]; ok {
+			return results
+		}
+		results := proceed(args)
+		cache[key] = results
+		return results
+	})
+	obj := goop.New()
+	obj.SetSuper(proto)
+	for i := 0; i < 3; i++ {
+		if result := obj.Call("square", 7)[0].(int); result != 49 {
+			t.Fatalf("Expected %d but saw %d", 49, result)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("Expected %d but saw %d", 1, calls)
+	}
+
+	// Ensure that a hook of the wrong type is rejected.
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected AddHook to panic")
+		}
+	}()
+	obj.AddHook(goop.Before, "square", func(this goop.Object) {})
+}
@@ -0,0 +1,71 @@
+// This file provides support for converting objects to and from JSON.
+
+package goop
+
+import "bytes"
+import "encoding/json"
+import "math"
+
+// MarshalJSON encodes an object's data members as a JSON object.  The
+// members are those returned by Contents(false): inherited members are
+// included, overridden members are replaced by their overriding
+// values, and method functions are omitted.  Members whose values are
+// themselves objects are encoded recursively.
+func (obj Object) MarshalJSON() ([]byte, error) {
+	if obj.Implementation == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(obj.Contents(false))
+}
+
+// UnmarshalJSON decodes a JSON object and Sets each of its fields as a
+// member of the object, allocating the object first if necessary.
+// Existing members not mentioned in the JSON data are left untouched.
+// Nested JSON objects are decoded as goop objects, integral numbers
+// as ints, and all other numbers as float64s.
+func (obj *Object) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var members map[string]interface{}
+	if err := dec.Decode(&members); err != nil {
+		return err
+	}
+	if members == nil {
+		return nil
+	}
+	if obj.Implementation == nil {
+		*obj = newObject()
+	}
+	for key, val := range members {
+		if err := obj.TrySet(key, fromJSONValue(val)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromJSONValue converts a value produced by a JSON decoder to the
+// form in which UnmarshalJSON stores it in an object.
+func fromJSONValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		nested := newObject()
+		for key, nestedVal := range v {
+			nested.Implementation.symbolTable.put(key, fromJSONValue(nestedVal))
+		}
+		return nested
+	case []interface{}:
+		for i, elt := range v {
+			v[i] = fromJSONValue(elt)
+		}
+		return v
+	default:
+		return val
+	}
+}
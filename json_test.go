@@ -0,0 +1,53 @@
+// This file tests converting objects to and from JSON.
+
+package goop_test
+
+import (
+	"encoding/json"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test a round trip of an object through JSON.
+func TestJSONRoundTrip(t *testing.T) {
+	// Encode a point with an inherited member, a nested object,
+	// and a method.
+	parent := goop.New()
+	parent.Set("label", "origin")
+	point := goop.New()
+	point.SetSuper(parent)
+	point.Set("x", 3)
+	point.Set("y", 4)
+	point.Set("scale", 0.5)
+	point.Set("length", func(this goop.Object) int { return 5 })
+	color := goop.New()
+	color.Set("name", "red")
+	point.Set("color", color)
+	data, err := json.Marshal(point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"color":{"name":"red"},"label":"origin","scale":0.5,"x":3,"y":4}`
+	if string(data) != expected {
+		t.Fatalf("Expected %s but saw %s", expected, data)
+	}
+
+	// Decode the point and ensure that it has the same values.
+	var decoded goop.Object
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if result := decoded.Get("x").(int); result != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, result)
+	}
+	if result := decoded.Get("y").(int); result != 4 {
+		t.Fatalf("Expected %d but saw %v", 4, result)
+	}
+	if result := decoded.Get("scale").(float64); result != 0.5 {
+		t.Fatalf("Expected %.1f but saw %v", 0.5, result)
+	}
+	nested := decoded.Get("color").(goop.Object)
+	if result := nested.Get("name").(string); result != "red" {
+		t.Fatalf("Expected %q but saw %v", "red", result)
+	}
+}
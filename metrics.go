@@ -0,0 +1,148 @@
+// This file implements optional instrumentation of object creation,
+// member access, and method dispatch.
+
+package goop
+
+import "expvar"
+import "strconv"
+import "sync/atomic"
+
+// A Metrics receives notification of runtime events for the purpose
+// of monitoring how heavily a program relies on dynamic dispatch.  A
+// Metrics is installed with SetMetrics.  Its methods may be invoked
+// concurrently from multiple goroutines and should return quickly.
+type Metrics interface {
+	// ObjectCreated is invoked whenever an object is created by New,
+	// Arena.New, Pool.Acquire, or a function built on them.
+	ObjectCreated()
+
+	// MemberRead is invoked whenever a member is read by Get,
+	// GetOK, GetSym, or a function built on them, including Call.
+	// depth is the number of prototype links traversed to find the
+	// member (0 for the object's own members) or -1 if the member
+	// was not found.
+	MemberRead(depth int)
+
+	// MemberWritten is invoked whenever a member is assigned by Set,
+	// TrySet, SetSym, or a function built on them.
+	MemberWritten()
+
+	// MethodCalled is invoked whenever a method is invoked by Call,
+	// CallErr, CallSym, or a function built on them.
+	MethodCalled()
+
+	// DispatchMissed is invoked whenever a MetaFunction must search
+	// its functions for one that accepts its arguments because it has
+	// no cached result for the arguments' types.
+	DispatchMissed()
+}
+
+// metricsBox holds the installed Metrics.
+type metricsBox struct {
+	metrics Metrics
+}
+
+// installedMetrics points to the installed Metrics or is nil if none is
+// installed.
+var installedMetrics atomic.Pointer[metricsBox]
+
+// SetMetrics installs a Metrics that will be notified of subsequent
+// runtime events across all objects.  Passing nil disables
+// instrumentation, which is the default.  While a Metrics is
+// installed, each Get additionally walks the prototype chain to
+// determine the member's depth, so instrumentation is best enabled
+// only when needed.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		installedMetrics.Store(nil)
+		return
+	}
+	installedMetrics.Store(&metricsBox{metrics: m})
+}
+
+// activeMetrics returns the installed Metrics or nil if none is
+// installed.
+func activeMetrics() Metrics {
+	if box := installedMetrics.Load(); box != nil {
+		return box.metrics
+	}
+	return nil
+}
+
+// newID returns a new object ID, noting the creation of an object.
+func newID() uint64 {
+	if m := activeMetrics(); m != nil {
+		m.ObjectCreated()
+	}
+	return atomic.AddUint64(&lastID, 1)
+}
+
+// memberDepth returns the number of prototype links traversed to find
+// a member in the order in which Get searches or -1 if the member does
+// not exist.
+func (obj *Object) memberDepth(memberName string) int {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	ok := impl.symbolTable.has(memberName)
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	if ok {
+		return 0
+	}
+	for _, parent := range prototypes {
+		if depth := parent.memberDepth(memberName); depth >= 0 {
+			return depth + 1
+		}
+	}
+	return -1
+}
+
+// An ExpvarMetrics is a Metrics that publishes its counts via the
+// expvar package, which makes them available at /debug/vars on a
+// program's HTTP server.  The published map contains the counters
+// "objects", "gets", "sets", "calls", and "dispatchMisses" and a map,
+// "getDepths", from a prototype depth (or "-1" for a missing member)
+// to the number of Gets that found a member at that depth.
+type ExpvarMetrics struct {
+	vars   *expvar.Map // Published map of all counters
+	depths *expvar.Map // Map from a prototype depth to a count of Gets
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics whose counts are published
+// under a given name.  Like expvar.Publish, NewExpvarMetrics panics if
+// the name is already in use.  Install the result with SetMetrics.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	m := &ExpvarMetrics{vars: expvar.NewMap(name), depths: new(expvar.Map)}
+	for _, key := range []string{"objects", "gets", "sets", "calls", "dispatchMisses"} {
+		m.vars.Add(key, 0)
+	}
+	m.vars.Set("getDepths", m.depths)
+	return m
+}
+
+// ObjectCreated increments the "objects" counter.
+func (m *ExpvarMetrics) ObjectCreated() {
+	m.vars.Add("objects", 1)
+}
+
+// MemberRead increments the "gets" counter and the given depth's
+// count in "getDepths".
+func (m *ExpvarMetrics) MemberRead(depth int) {
+	m.vars.Add("gets", 1)
+	m.depths.Add(strconv.Itoa(depth), 1)
+}
+
+// MemberWritten increments the "sets" counter.
+func (m *ExpvarMetrics) MemberWritten() {
+	m.vars.Add("sets", 1)
+}
+
+// MethodCalled increments the "calls" counter.
+func (m *ExpvarMetrics) MethodCalled() {
+	m.vars.Add("calls", 1)
+}
+
+// DispatchMissed increments the "dispatchMisses" counter.
+func (m *ExpvarMetrics) DispatchMissed() {
+	m.vars.Add("dispatchMisses", 1)
+}
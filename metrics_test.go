@@ -0,0 +1,84 @@
+// This file tests instrumentation of the goop runtime.
+
+package goop_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// A countingMetrics is a goop.Metrics that merely counts events.
+type countingMetrics struct {
+	objects, gets, sets, calls, misses int
+	depths                             map[int]int
+}
+
+func (m *countingMetrics) ObjectCreated()       { m.objects++ }
+func (m *countingMetrics) MemberRead(depth int) { m.gets++; m.depths[depth]++ }
+func (m *countingMetrics) MemberWritten()       { m.sets++ }
+func (m *countingMetrics) MethodCalled()        { m.calls++ }
+func (m *countingMetrics) DispatchMissed()      { m.misses++ }
+
+// Test that a Metrics is notified of runtime events.
+func TestMetrics(t *testing.T) {
+	m := &countingMetrics{depths: make(map[int]int)}
+	goop.SetMetrics(m)
+	defer goop.SetMetrics(nil)
+	proto := goop.New()
+	proto.Set("greet", goop.CombineFunctions(func(this goop.Object, s string) string { return "Hello, " + s }))
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("x", 1)
+	obj.Get("x")
+	obj.Get("missing")
+	obj.Call("greet", "world")
+	if m.objects != 2 {
+		t.Fatalf("Expected %d but saw %d", 2, m.objects)
+	}
+	if m.sets != 2 {
+		t.Fatalf("Expected %d but saw %d", 2, m.sets)
+	}
+	if m.calls != 1 {
+		t.Fatalf("Expected %d but saw %d", 1, m.calls)
+	}
+	if m.gets != 3 || m.depths[0] != 1 || m.depths[1] != 1 || m.depths[-1] != 1 {
+		t.Fatalf("Expected one Get at each of depths 0, 1, and -1 but saw %v", m.depths)
+	}
+	if m.misses != 0 {
+		t.Fatalf("Expected %d but saw %d", 0, m.misses)
+	}
+	obj.Call("greet", []byte("world"))
+	if m.misses != 1 {
+		t.Fatalf("Expected %d but saw %d", 1, m.misses)
+	}
+
+	// Uninstalling the Metrics stops notification.
+	goop.SetMetrics(nil)
+	goop.New()
+	if m.objects != 2 {
+		t.Fatalf("Expected %d but saw %d", 2, m.objects)
+	}
+}
+
+// Test publishing metrics via expvar.
+func TestExpvarMetrics(t *testing.T) {
+	goop.SetMetrics(goop.NewExpvarMetrics("goop_test"))
+	defer goop.SetMetrics(nil)
+	obj := goop.New()
+	obj.Set("x", 1)
+	obj.Get("x")
+	var vars struct {
+		Objects   int
+		Sets      int
+		Gets      int
+		GetDepths map[string]int
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("goop_test").String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars.Objects != 1 || vars.Sets != 1 || vars.Gets != 1 || vars.GetDepths["0"] != 1 {
+		t.Fatalf("Expected one object, Set, and depth-0 Get but saw %+v", vars)
+	}
+}
@@ -0,0 +1,95 @@
+// This file implements mixins, which compose an object from the
+// members of other objects rather than inheriting from them.
+
+package goop
+
+import "errors"
+import "fmt"
+import "sort"
+import "strings"
+
+// ErrConflict is returned by an attempt to mix in two objects that
+// define a member with the same name.
+var ErrConflict = errors.New("Conflicting members")
+
+// A ConflictError lists the members defined by more than one of the
+// objects passed to Mixin.  It wraps ErrConflict.
+type ConflictError struct {
+	Members []string // Names of the conflicting members in lexical order
+}
+
+// Error returns a description of a ConflictError.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrConflict, strings.Join(e.Members, ", "))
+}
+
+// Unwrap returns ErrConflict.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Mixin copies into the object all members of each of a list of
+// traits, including the members the traits inherit from their own
+// prototypes.  Unlike a prototype, a trait is consulted only once:
+// later changes to the trait do not affect the object.  Members that
+// the object itself already contains take precedence over the traits'
+// members and are left unmodified.  If two traits define a member with
+// the same name but different values, as by Equal, and the object does
+// not itself contain that member, Mixin copies nothing and returns a
+// *ConflictError listing every such member.  Traits that share a
+// member, for example, by inheriting it from a common prototype, thus
+// do not conflict.  A conflict is resolved by defining the member in
+// the object before calling Mixin, for example, by Setting it to one
+// trait's version or to a method that invokes both.  Mixin copies
+// members in lexical order of their names and returns any error that
+// Set would return, in which case the members preceding the failing
+// one have already been copied.
+func (obj *Object) Mixin(traits ...Object) error {
+	// Gather the members of each distinct trait, noting conflicts.
+	impl := obj.Implementation
+	members := make(map[string]interface{})
+	conflicts := make(map[string]bool)
+	visited := make(map[*internal]bool, len(traits))
+	for _, trait := range traits {
+		if visited[trait.Implementation] {
+			continue
+		}
+		visited[trait.Implementation] = true
+		for name, value := range trait.Contents(true) {
+			impl.lock.RLock()
+			_, defined := impl.symbolTable.get(name)
+			impl.lock.RUnlock()
+			if defined {
+				continue
+			}
+			if seen, ok := members[name]; ok {
+				if !valuesEqual(seen, value, true, nil, make(map[[2]*internal]bool)) {
+					conflicts[name] = true
+				}
+				continue
+			}
+			members[name] = value
+		}
+	}
+	if len(conflicts) > 0 {
+		names := make([]string, 0, len(conflicts))
+		for name := range conflicts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return &ConflictError{Members: names}
+	}
+
+	// Copy the members into the object.
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := obj.TrySet(name, members[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
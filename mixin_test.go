@@ -0,0 +1,86 @@
+// This file tests composing objects from traits.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test copying members from traits and resolving conflicts.
+func TestMixin(t *testing.T) {
+	// Define two traits that share a member name.
+	walker := goop.New()
+	walker.Set("walk", func(this goop.Object) string { return "walking" })
+	walker.Set("describe", func(this goop.Object) string { return "walker" })
+	swimmer := goop.New()
+	swimmer.Set("swim", func(this goop.Object) string { return "swimming" })
+	swimmer.Set("describe", func(this goop.Object) string { return "swimmer" })
+
+	// Ensure that the conflict is reported and nothing is copied.
+	duck := goop.New()
+	err := duck.Mixin(walker, swimmer)
+	var conflict *goop.ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, goop.ErrConflict) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrConflict, err)
+	}
+	if len(conflict.Members) != 1 || conflict.Members[0] != "describe" {
+		t.Fatalf("Expected [describe] but saw %v", conflict.Members)
+	}
+	if _, ok := duck.GetOK("walk"); ok {
+		t.Fatalf("Expected %v but saw a walk method", goop.ErrNotFound)
+	}
+
+	// Ensure that defining the member in the object resolves the
+	// conflict.
+	duck.Set("describe", func(this goop.Object) string {
+		return walker.Call("describe")[0].(string) + "/" + swimmer.Call("describe")[0].(string)
+	})
+	if err := duck.Mixin(walker, swimmer); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	for method, expected := range map[string]string{
+		"walk":     "walking",
+		"swim":     "swimming",
+		"describe": "walker/swimmer",
+	} {
+		if result := duck.Call(method)[0].(string); result != expected {
+			t.Fatalf("Expected %q but saw %q", expected, result)
+		}
+	}
+
+	// Ensure that the object does not track later changes to a trait.
+	walker.Set("walk", func(this goop.Object) string { return "running" })
+	if result := duck.Call("walk")[0].(string); result != "walking" {
+		t.Fatalf("Expected %q but saw %q", "walking", result)
+	}
+}
+
+// Test that traits inheriting the same member from a common prototype
+// do not conflict.
+func TestMixinSharedMember(t *testing.T) {
+	base := goop.New()
+	base.Set("describe", func(this goop.Object) string { return "animal" })
+	base.Set("legs", 4)
+	walker := goop.New()
+	walker.SetSuper(base)
+	walker.Set("walk", func(this goop.Object) string { return "walking" })
+	swimmer := goop.New()
+	swimmer.SetSuper(base)
+	swimmer.Set("swim", func(this goop.Object) string { return "swimming" })
+	swimmer.Set("legs", 0)
+	duck := goop.New()
+	err := duck.Mixin(walker, swimmer)
+	var conflict *goop.ConflictError
+	if !errors.As(err, &conflict) || len(conflict.Members) != 1 || conflict.Members[0] != "legs" {
+		t.Fatalf("Expected [legs] but saw %v", err)
+	}
+	duck.Set("legs", 2)
+	if err := duck.Mixin(walker, swimmer); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if result := duck.Call("describe")[0].(string); result != "animal" {
+		t.Fatalf("Expected %q but saw %q", "animal", result)
+	}
+}
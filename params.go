@@ -0,0 +1,189 @@
+// This file implements calling methods with arguments passed by name.
+
+package goop
+
+import "fmt"
+import "reflect"
+
+// A KW maps parameter names to argument values for CallKW.
+type KW map[string]interface{}
+
+// WithParams returns a MetaFunction that invokes a function (or
+// dispatches among the functions of a MetaFunction produced by
+// CombineFunctions) and that records names for the function's
+// parameters so the resulting method can be invoked with CallKW.  The
+// names apply, in order, to the parameters that follow the function's
+// initial Object parameter.  The original MetaFunction, if any, is not
+// modified.
+func WithParams(function interface{}, names ...string) MetaFunction {
+	d := dispatcherFor(function)
+	d.params = append([]string(nil), names...)
+	return d.metaFunction()
+}
+
+// WithDefaults returns a MetaFunction that invokes a function (or
+// dispatches among the functions of a MetaFunction produced by
+// CombineFunctions) and that supplies default values for the
+// function's trailing parameters.  The defaults apply to the last
+// len(defaults) parameters, so a method defined with
+//
+//	obj.Set("resize", goop.WithDefaults(
+//	        func(this goop.Object, width, height int, mode string) { ... },
+//	        0, "auto"))
+//
+// can be invoked with obj.Call("resize", 640), obj.Call("resize", 640,
+// 480), or obj.Call("resize", 640, 480, "fill").  Defaults are used
+// only when no function accepts the arguments as given.  WithDefaults
+// composes with WithParams, and the original MetaFunction, if any, is
+// not modified.
+func WithDefaults(function interface{}, defaults ...interface{}) MetaFunction {
+	d := dispatcherFor(function)
+	d.defaultArgs = append([]interface{}{}, defaults...)
+	return d.metaFunction()
+}
+
+// dispatcherFor returns a new dispatcher for a function or a copy of
+// the dispatcher underlying a MetaFunction produced by
+// CombineFunctions.
+func dispatcherFor(function interface{}) *dispatcher {
+	if mf, ok := function.(MetaFunction); ok && mf.dispatcher() != nil {
+		return mf.dispatcher().clone()
+	}
+	d := &dispatcher{}
+	d.setTargets([]*dispatchTarget{newDispatchTarget(function)})
+	return d
+}
+
+// CallKW invokes a method whose parameter names were registered with
+// WithParams, passing each argument in kw to the parameter of the same
+// name.  A function with n parameters following its initial Object
+// parameter takes the first n registered names, so the functions of a
+// MetaFunction produced by CombineFunctions may take different numbers
+// of arguments.  CallKW invokes the first such function that has a
+// parameter for every name in kw, that has a default value, as
+// registered with WithDefaults, for every parameter absent from kw
+// other than a variadic parameter, and that accepts the arguments'
+// types.  It invokes that function directly, as CallErr would, rather
+// than dispatching among the MetaFunction's functions anew.  CallKW
+// returns the same errors as CallErr as well as an error wrapping
+// ErrBadArguments if the method has no registered parameter names or
+// if kw names a parameter no function has or omits a required one.
+// The error is an *ArgumentError if the functions whose parameters
+// match kw by name do not accept the arguments' types.
+func (obj *Object) CallKW(methodName string, kw KW) ([]interface{}, error) {
+	userFuncIface, ok := obj.GetOK(methodName)
+	if !ok {
+		return nil, &NotFoundError{Member: methodName, Method: true}
+	}
+	var d *dispatcher
+	if mf, ok := userFuncIface.(MetaFunction); ok {
+		d = mf.dispatcher()
+	}
+	if d == nil || d.params == nil {
+		return nil, fmt.Errorf("Method %q: %w (no parameter names are registered)", methodName, ErrBadArguments)
+	}
+
+	// Find the first function that accepts the arguments, arranged
+	// in the order of its parameters, and invoke it.
+	d.lock.RLock()
+	targets := d.targets
+	params := d.params
+	defaultArgs := d.defaultArgs
+	d.lock.RUnlock()
+	var typeErr *ArgumentError
+	var firstErr error
+	for _, t := range targets {
+		arguments, err := t.keywordArguments(params, defaultArgs, kw)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		argList := append([]interface{}{*obj}, arguments...)
+		if !d.accepts(t, argList) {
+			if typeErr == nil {
+				typeErr = newArgumentError(methodName, nil, argList)
+			}
+			typeErr.Expected = append(typeErr.Expected, t.funcType)
+			continue
+		}
+		return obj.callErrWith(methodName, arguments, func(obj *Object, methodName string, arguments []interface{}) ([]interface{}, error) {
+			argList := append([]interface{}{*obj}, arguments...)
+			if !d.accepts(t, argList) {
+				return nil, newArgumentError(methodName, []reflect.Type{t.funcType}, argList)
+			}
+			return t.call(argList, d.promote), nil
+		})
+	}
+	if typeErr != nil {
+		return nil, typeErr
+	}
+	return nil, fmt.Errorf("Method %q: %w", methodName, firstErr)
+}
+
+// accepts returns true if a target function accepts a list of
+// arguments, promoting numeric arguments if the dispatcher does.
+func (d *dispatcher) accepts(t *dispatchTarget, argList []interface{}) bool {
+	if d.promote {
+		_, ok := argumentsCost(t.funcType, argList)
+		return ok
+	}
+	return acceptsArguments(t.funcType, argList)
+}
+
+// keywordArguments arranges arguments passed by name in the order of
+// the target function's parameters, which take their names from the
+// start of params.  Parameters absent from kw receive their default
+// values, which apply to the function's trailing parameters as in
+// dispatcher.resolveDefaultArgs.  keywordArguments returns an error
+// wrapping ErrBadArguments if the function has no parameter for a name
+// in kw or if a parameter without a default value is absent from kw.
+func (t *dispatchTarget) keywordArguments(params []string, defaultArgs []interface{}, kw KW) ([]interface{}, error) {
+	// Determine the names of the function's parameters.
+	funcType := t.funcType
+	numParams := funcType.NumIn()
+	if numParams > 0 && funcType.In(0) == objectType {
+		numParams--
+	}
+	if numParams > len(params) {
+		return nil, fmt.Errorf("%w (%d parameters but only %d names)", ErrBadArguments, numParams, len(params))
+	}
+	names := params[:numParams]
+	for name := range kw {
+		if !containsString(names, name) {
+			return nil, fmt.Errorf("%w (no parameter named %q)", ErrBadArguments, name)
+		}
+	}
+
+	// Arrange the arguments.
+	firstDefault := numParams - len(defaultArgs)
+	if funcType.IsVariadic() {
+		firstDefault = numParams
+	}
+	arguments := make([]interface{}, 0, numParams)
+	for i, name := range names {
+		value, ok := kw[name]
+		switch {
+		case ok:
+			arguments = append(arguments, value)
+		case funcType.IsVariadic() && i == numParams-1:
+			// Pass no variadic arguments.
+		case i >= firstDefault:
+			arguments = append(arguments, defaultArgs[i-firstDefault])
+		default:
+			return nil, fmt.Errorf("%w (no argument for parameter %q)", ErrBadArguments, name)
+		}
+	}
+	return arguments, nil
+}
+
+// containsString returns true if a list contains a string.
+func containsString(list []string, s string) bool {
+	for _, elt := range list {
+		if elt == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+// This file tests calling methods with arguments passed by name.
+
+package goop_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test passing arguments by name.
+func TestCallKW(t *testing.T) {
+	obj := goop.New()
+	obj.Set("point", goop.WithParams(func(self goop.Object, x, y int, label string) string {
+		return fmt.Sprintf("%s(%d, %d)", label, x, y)
+	}, "x", "y", "label"))
+
+	// Ensure that arguments are passed in parameter order and that
+	// omitted arguments are rejected.
+	result, err := obj.CallKW("point", goop.KW{"label": "p", "y": 5, "x": 3})
+	if err != nil || result[0].(string) != "p(3, 5)" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "p(3, 5)", result, err)
+	}
+	if _, err = obj.CallKW("point", goop.KW{"y": 2}); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+
+	// Ensure that positional calls still work and that unknown
+	// names are rejected.
+	if result := obj.Call("point", 1, 2, "q")[0].(string); result != "q(1, 2)" {
+		t.Fatalf("Expected %q but saw %v", "q(1, 2)", result)
+	}
+	if _, err = obj.CallKW("point", goop.KW{"z": 1}); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test omitting arguments that have default values.
+func TestWithDefaults(t *testing.T) {
+	obj := goop.New()
+	obj.Set("resize", goop.WithParams(goop.WithDefaults(
+		func(self goop.Object, width, height int, mode string) string {
+			return fmt.Sprintf("%dx%d %s", width, height, mode)
+		}, 100, "auto"), "width", "height", "mode"))
+
+	// Ensure that positional calls fill in trailing defaults.
+	if result := obj.Call("resize", 640)[0].(string); result != "640x100 auto" {
+		t.Fatalf("Expected %q but saw %v", "640x100 auto", result)
+	}
+	if result := obj.Call("resize", 640, 480)[0].(string); result != "640x480 auto" {
+		t.Fatalf("Expected %q but saw %v", "640x480 auto", result)
+	}
+	if result := obj.Call("resize", 640, 480, "fill")[0].(string); result != "640x480 fill" {
+		t.Fatalf("Expected %q but saw %v", "640x480 fill", result)
+	}
+
+	// Ensure that named calls fill in defaults anywhere but still
+	// require parameters without defaults.
+	result, err := obj.CallKW("resize", goop.KW{"mode": "fit", "width": 50})
+	if err != nil || result[0].(string) != "50x100 fit" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "50x100 fit", result, err)
+	}
+	if _, err = obj.CallKW("resize", goop.KW{"mode": "fit"}); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test passing arguments by name to overloaded functions.
+func TestCallKWOverloads(t *testing.T) {
+	obj := goop.New()
+	obj.Set("area", goop.WithParams(goop.CombineFunctions(
+		func(self goop.Object, width float64) string {
+			return fmt.Sprintf("square %g", width*width)
+		},
+		func(self goop.Object, width, height float64) string {
+			return fmt.Sprintf("rectangle %g", width*height)
+		},
+	), "width", "height"))
+	result, err := obj.CallKW("area", goop.KW{"width": 3.0})
+	if err != nil || result[0].(string) != "square 9" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "square 9", result, err)
+	}
+	result, err = obj.CallKW("area", goop.KW{"height": 2.0, "width": 3.0})
+	if err != nil || result[0].(string) != "rectangle 6" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "rectangle 6", result, err)
+	}
+	if _, err = obj.CallKW("area", goop.KW{"height": 2.0}); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
+
+// Test that CallKW invokes the function its arguments matched rather
+// than dispatching anew.
+func TestCallKWMatchedFunction(t *testing.T) {
+	obj := goop.New()
+	obj.Set("show", goop.WithParams(goop.CombineFunctions(
+		func(self goop.Object, value interface{}) string { return "any" },
+		func(self goop.Object, value int, verbose bool) string { return "int" },
+		func(self goop.Object, value int) string { return "exact" },
+	), "value", "verbose"))
+
+	// Dispatch prefers the function of the arguments' exact types,
+	// but CallKW invokes the first function that matches.
+	if result := obj.Call("show", 1)[0].(string); result != "exact" {
+		t.Fatalf("Expected %q but saw %v", "exact", result)
+	}
+	result, err := obj.CallKW("show", goop.KW{"value": 1})
+	if err != nil || result[0].(string) != "any" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "any", result, err)
+	}
+	result, err = obj.CallKW("show", goop.KW{"verbose": true, "value": 1})
+	if err != nil || result[0].(string) != "int" {
+		t.Fatalf("Expected ([%q], nil) but saw (%v, %v)", "int", result, err)
+	}
+
+	// Ensure that arguments of the wrong types are reported with an
+	// ArgumentError and that other failures wrap ErrBadArguments.
+	var argErr *goop.ArgumentError
+	if _, err = obj.CallKW("show", goop.KW{"value": 1, "verbose": "yes"}); !errors.As(err, &argErr) {
+		t.Fatalf("Expected an ArgumentError but saw %v", err)
+	}
+	obj.Set("plain", func(self goop.Object) {})
+	if _, err = obj.CallKW("plain", goop.KW{}); !errors.Is(err, goop.ErrBadArguments) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrBadArguments, err)
+	}
+}
@@ -0,0 +1,76 @@
+// This file implements pools of reusable objects.
+
+package goop
+
+import "sync"
+import "sync/atomic"
+
+// A Pool recycles the storage of released objects to reduce the cost
+// of creating many short-lived objects.  The zero value is an empty
+// pool ready to use.  A Pool is safe for concurrent use by multiple
+// goroutines.
+type Pool struct {
+	free sync.Pool // Released objects' internal representations
+}
+
+// Acquire is like New but reuses the storage of a previously released
+// object if one is available.  An acquired object is indistinguishable
+// from a new one; in particular, it has a new ID.
+func (p *Pool) Acquire(constructor ...interface{}) Object {
+	impl, ok := p.free.Get().(*internal)
+	if !ok {
+		return construct(newObject(), constructor)
+	}
+	impl.id = newID()
+	return construct(Object{Implementation: impl}, constructor)
+}
+
+// Release returns an object's storage to the pool for reuse by
+// Acquire.  The object's members, prototypes, watchers, hooks, and
+// all other state are discarded.  The caller must ensure that no
+// references to the object remain in use, as any such reference will
+// observe the object's storage being reused by an unrelated object.
+// Release does not invoke the object's Finalize method; call Destroy
+// first if necessary.
+func (p *Pool) Release(obj Object) {
+	impl := obj.Implementation
+	impl.setFinalizer(nil)
+	impl.lock.Lock()
+	impl.reset()
+	impl.lock.Unlock()
+	p.free.Put(impl)
+}
+
+// reset returns an object to the state of a newly allocated object,
+// reusing its storage for member values if possible.  The caller must
+// hold the object's lock.
+func (impl *internal) reset() {
+	for _, hooks := range impl.hooks {
+		atomic.AddInt64(&numHooks, -int64(len(hooks)))
+	}
+	table := newMemberTable()
+	if !impl.tableShared && impl.symbolTable.shape != nil {
+		values := impl.symbolTable.values
+		for i := range values {
+			values[i] = nil
+		}
+		table.values = values[:0]
+	}
+	impl.symbolTable = table
+	impl.prototypes = nil
+	impl.dependents = nil
+	impl.watchers = nil
+	impl.hooks = nil
+	impl.handlers = nil
+	impl.shared = nil
+	impl.frozen = false
+	impl.sealed = false
+	impl.tableShared = false
+	impl.history = nil
+	impl.destroyed = false
+	impl.prototypesChanged()
+	impl.lookups.Store(nil)
+	impl.readMostly.Store(false)
+	impl.tracer.Store(nil)
+	impl.schema.Store(nil)
+}
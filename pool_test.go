@@ -0,0 +1,29 @@
+// This file tests pools of reusable objects.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test acquiring, releasing, and reacquiring objects.
+func TestPool(t *testing.T) {
+	var pool goop.Pool
+	particle := func(this goop.Object, x, y float64) {
+		this.Set("x", x)
+		this.Set("y", y)
+	}
+	for i := 0; i < 100; i++ {
+		obj := pool.Acquire(particle, float64(i), 2.0)
+		if obj.HasMember("frozen") || obj.IsFrozen() {
+			t.Fatalf("Expected a fresh object")
+		}
+		if result := obj.Get("x").(float64); result != float64(i) {
+			t.Fatalf("Expected %.1f but saw %v", float64(i), result)
+		}
+		obj.Set("frozen", true)
+		obj.Freeze()
+		pool.Release(obj)
+	}
+}
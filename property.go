@@ -0,0 +1,170 @@
+// This file implements properties, object members whose reads and
+// writes are routed through user-supplied functions.
+
+package goop
+
+import "fmt"
+import "sync"
+
+// A Descriptor specifies how a property defined by DefineProperty is
+// read and written.
+type Descriptor struct {
+	Get        func(this Object) interface{}              // Function that produces the property's value, or nil to use the stored value
+	Set        func(this Object, value interface{}) error // Function that validates a new value before it is stored, or nil
+	Enumerable bool                                       // true if Contents and Keys include the property
+}
+
+// A property represents a member defined by DefineProperty or
+// SetConst.
+type property struct {
+	desc     Descriptor  // Functions and flags describing the property
+	constant bool        // true if the member was defined by SetConst
+	lock     sync.Mutex  // Lock protecting value
+	value    interface{} // Most recently stored value
+}
+
+// A ReadOnlyError describes an attempt to modify or remove a member
+// defined by SetConst.  It wraps ErrReadOnly.
+type ReadOnlyError struct {
+	Member string // Name of the member
+}
+
+// Error returns a description of a ReadOnlyError.
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s: %q", ErrReadOnly, e.Member)
+}
+
+// Unwrap returns ErrReadOnly.
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// readOnly returns true if the property has a getter but no setter.
+func (p *property) readOnly() bool {
+	return p.desc.Get != nil && p.desc.Set == nil
+}
+
+// hasAccessors returns true if the property has a getter or a setter.
+func (p *property) hasAccessors() bool {
+	return p.desc.Get != nil || p.desc.Set != nil
+}
+
+// read returns the property's value as seen by Get.
+func (p *property) read(this Object) interface{} {
+	if p.desc.Get != nil {
+		return p.desc.Get(this)
+	}
+	return p.get()
+}
+
+// get returns the property's stored value.
+func (p *property) get() interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.value
+}
+
+// set replaces the property's stored value.
+func (p *property) set(value interface{}) error {
+	p.lock.Lock()
+	p.value = value
+	p.lock.Unlock()
+	return nil
+}
+
+// validate returns an error if the property does not accept a new
+// value.  It invokes the property's setter, so the caller must not
+// hold the object's lock.
+func (p *property) validate(this Object, value interface{}) error {
+	switch {
+	case p.readOnly():
+		return ErrReadOnly
+	case p.desc.Set != nil:
+		return p.desc.Set(this, value)
+	default:
+		return nil
+	}
+}
+
+// DefineProperty defines a member whose reads and writes are routed
+// through the functions in a Descriptor.  Get returns the result of
+// the descriptor's Get function, which is passed the object on which
+// Get was invoked even if that object inherits the property, or, if
+// the Get function is nil, the value most recently Set.  Set first passes the new value to the descriptor's
+// Set function, if any, and fails with the error it returns; otherwise,
+// the value is stored.  Setting a property that has a Get function but
+// no Set function fails with ErrReadOnly.  The accessors are invoked
+// without any locks held, so they may freely access the object.  A
+// property that is not Enumerable is omitted from Contents and Keys
+// but can still be read by name.  Properties apply only to the object
+// that defines them; Setting a property inherited from a prototype
+// stores an ordinary member in the descendant.  Use Unset to remove a
+// property.  DefineProperty panics with ErrFrozen if the object is
+// frozen and with ErrSealed if the object is sealed and does not
+// already contain the member.
+func (obj *Object) DefineProperty(name string, desc Descriptor) {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if err := impl.checkDefine(name); err != nil {
+		panic(err)
+	}
+	impl.recordHistory()
+	impl.removeComputed(name)
+	impl.ownTable()
+	impl.symbolTable.put(name, &property{desc: desc})
+	impl.invalidateLookups()
+	impl.memberChanged(name)
+}
+
+// SetConst associates a value with the name of an object member and
+// makes the member a constant.  Subsequent attempts to Set, Add to,
+// Unset, or otherwise redefine the member on the object fail with a
+// *ReadOnlyError.  Descendants of the object can still shadow the
+// member with members of their own, as Setting an inherited member
+// always does.  A constant reads like any other member and appears in
+// Contents and Keys.  SetConst notifies the member's watchers and
+// panics with the same errors as Set, including a *ReadOnlyError if
+// the member is already a constant.
+func (obj *Object) SetConst(memberName string, value interface{}) {
+	impl := obj.Implementation
+	if err := impl.checkSchema(memberName, value); err != nil {
+		panic(err)
+	}
+	impl.lock.Lock()
+	if err := impl.checkDefine(memberName); err != nil {
+		impl.lock.Unlock()
+		panic(err)
+	}
+	old, ok := impl.symbolTable.get(memberName)
+	if !ok {
+		old = ErrNotFound
+	}
+	impl.recordHistory()
+	impl.removeComputed(memberName)
+	impl.ownTable()
+	impl.symbolTable.put(memberName, &property{
+		desc:     Descriptor{Enumerable: true},
+		constant: true,
+		value:    value,
+	})
+	impl.invalidateLookups()
+	impl.memberChanged(memberName)
+	watchers := impl.watchers[memberName]
+	impl.lock.Unlock()
+	notifyWatchers(watchers, memberValue(*obj, old), value)
+}
+
+// isConstant returns true if a value stored in a symbol table is a
+// member defined by SetConst.
+func isConstant(stored interface{}) bool {
+	p, ok := stored.(*property)
+	return ok && p.constant
+}
+
+// hidden returns true if a value stored in a symbol table is a
+// property that is not enumerable.
+func hidden(stored interface{}) bool {
+	p, ok := stored.(*property)
+	return ok && !p.desc.Enumerable
+}
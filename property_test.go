@@ -0,0 +1,144 @@
+// This file tests object members defined by property descriptors.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test routing reads and writes through property accessors.
+func TestDefineProperty(t *testing.T) {
+	// Define a temperature that rejects values below absolute zero
+	// and a read-only Fahrenheit view of it.
+	errTooCold := errors.New("Below absolute zero")
+	temp := goop.New()
+	temp.DefineProperty("celsius", goop.Descriptor{
+		Set: func(this goop.Object, value interface{}) error {
+			if value.(float64) < -273.15 {
+				return errTooCold
+			}
+			return nil
+		},
+		Enumerable: true,
+	})
+	temp.DefineProperty("fahrenheit", goop.Descriptor{
+		Get: func(this goop.Object) interface{} {
+			return this.Get("celsius").(float64)*9/5 + 32
+		},
+	})
+
+	// Ensure that valid writes are stored and invalid writes are
+	// rejected.
+	temp.Set("celsius", 100.0)
+	if result := temp.Get("fahrenheit").(float64); result != 212.0 {
+		t.Fatalf("Expected %.1f but saw %v", 212.0, result)
+	}
+	if err := temp.TrySet("celsius", -300.0); err != errTooCold {
+		t.Fatalf("Expected %v but saw %v", errTooCold, err)
+	}
+	if result := temp.Get("celsius").(float64); result != 100.0 {
+		t.Fatalf("Expected %.1f but saw %v", 100.0, result)
+	}
+	if err := temp.TrySet("fahrenheit", 0.0); !errors.Is(err, goop.ErrReadOnly) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrReadOnly, err)
+	}
+
+	// Ensure that only enumerable properties are listed.
+	if keys := temp.Keys(false); len(keys) != 1 || keys[0] != "celsius" {
+		t.Fatalf("Expected [celsius] but saw %v", keys)
+	}
+	if contents := temp.Contents(false); len(contents) != 1 {
+		t.Fatalf("Expected %d member but saw %d", 1, len(contents))
+	}
+}
+
+// Test that constants cannot be modified but can be shadowed.
+func TestSetConst(t *testing.T) {
+	proto := goop.New()
+	proto.Set("pi", 3)
+	var seen []interface{}
+	proto.Watch("pi", func(old, new interface{}) { seen = append(seen, old, new) })
+	proto.SetConst("pi", 3.14159)
+	if result := proto.Get("pi"); result != 3.14159 {
+		t.Fatalf("Expected %v but saw %v", 3.14159, result)
+	}
+	if len(seen) != 2 || seen[0] != 3 || seen[1] != 3.14159 {
+		t.Fatalf("Expected %v but saw %v", []interface{}{3, 3.14159}, seen)
+	}
+	if contents := proto.Contents(false); contents["pi"] != 3.14159 {
+		t.Fatalf("Expected %v but saw %v", 3.14159, contents["pi"])
+	}
+
+	// Ensure that the constant can't be changed or removed.
+	err := proto.TrySet("pi", 3.0)
+	var roErr *goop.ReadOnlyError
+	if !errors.As(err, &roErr) || roErr.Member != "pi" || !errors.Is(err, goop.ErrReadOnly) {
+		t.Fatalf("Expected a ReadOnlyError but saw %v", err)
+	}
+	if err := proto.TryUnset("pi"); !errors.As(err, &roErr) {
+		t.Fatalf("Expected a ReadOnlyError but saw %v", err)
+	}
+	if _, err := proto.Add("pi", 1.0); !errors.As(err, &roErr) {
+		t.Fatalf("Expected a ReadOnlyError but saw %v", err)
+	}
+	clone := proto.Clone()
+	if err := clone.TrySet("pi", 3.0); !errors.As(err, &roErr) {
+		t.Fatalf("Expected a ReadOnlyError but saw %v", err)
+	}
+	func() {
+		defer func() {
+			if r, ok := recover().(*goop.ReadOnlyError); !ok || r.Member != "pi" {
+				t.Fatalf("Expected a ReadOnlyError but saw %v", r)
+			}
+		}()
+		proto.SetConst("pi", 3.0)
+	}()
+	if result := proto.Get("pi"); result != 3.14159 {
+		t.Fatalf("Expected %v but saw %v", 3.14159, result)
+	}
+
+	// Ensure that descendants can shadow the constant.
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("pi", 3)
+	if result := obj.Get("pi"); result != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, result)
+	}
+	obj.Unset("pi")
+	if result := obj.Get("pi"); result != 3.14159 {
+		t.Fatalf("Expected %v but saw %v", 3.14159, result)
+	}
+}
+
+// Test that Keys and Contents agree on constants and properties whose
+// values are functions.
+func TestKeysMatchContents(t *testing.T) {
+	obj := goop.New()
+	obj.Set("x", 1)
+	obj.Set("method", func(this goop.Object) {})
+	obj.SetConst("constMethod", func(this goop.Object) {})
+	obj.SetConst("constData", 2)
+	obj.DefineProperty("propMethod", goop.Descriptor{
+		Get:        func(this goop.Object) interface{} { return func() {} },
+		Enumerable: true,
+	})
+	obj.DefineProperty("propData", goop.Descriptor{Enumerable: true})
+	obj.Set("propData", 3)
+	for _, alsoMethods := range []bool{false, true} {
+		contents := obj.Contents(alsoMethods)
+		keys := obj.Keys(alsoMethods)
+		if len(keys) != len(contents) {
+			t.Fatalf("Expected %v but saw %v", contents, keys)
+		}
+		for _, key := range keys {
+			if _, ok := contents[key]; !ok {
+				t.Fatalf("Expected %v but saw %v", contents, keys)
+			}
+		}
+	}
+	if keys := obj.Keys(false); len(keys) != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, keys)
+	}
+}
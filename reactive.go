@@ -0,0 +1,34 @@
+// This file implements reactive bindings, which keep a member up to
+// date with the members it is computed from.
+
+package goop
+
+// BindMember binds the named member of an object to the result of a
+// function, which is called on the object immediately and again
+// whenever one of the members named in deps is changed on the object
+// by Set, Add, or Unset.  Each result is stored in the member as if by
+// Set, so unlike a member defined by DefineComputed, a bound member is
+// recomputed eagerly, can be read like any other member, and notifies
+// its own watchers (including other bindings that depend on it) when
+// it changes.  As with Watch, only changes to the object itself, not
+// to its prototypes, trigger recomputation.  A binding whose function
+// depends, directly or via other bindings, on the bound member itself
+// recurses without end.  BindMember panics under the same conditions
+// as Set.  It returns a function that removes the binding, leaving the
+// member's current value in place.  (BindMember is distinct from Bind,
+// which binds arguments to a method.)
+func BindMember(obj Object, memberName string, compute func(this Object) interface{}, deps ...string) (cancel func()) {
+	update := func(old, new interface{}) {
+		obj.Set(memberName, compute(obj))
+	}
+	cancels := make([]func(), len(deps))
+	for i, dep := range deps {
+		cancels[i] = obj.Watch(dep, update)
+	}
+	obj.Set(memberName, compute(obj))
+	return func() {
+		for _, c := range cancels {
+			c()
+		}
+	}
+}
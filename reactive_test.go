@@ -0,0 +1,45 @@
+// This file tests reactive bindings.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that bound members follow the members they depend on.
+func TestBindMember(t *testing.T) {
+	order := goop.New()
+	order.Set("price", 10)
+	order.Set("quantity", 2)
+	goop.BindMember(order, "subtotal", func(this goop.Object) interface{} {
+		return this.Get("price").(int) * this.Get("quantity").(int)
+	}, "price", "quantity")
+	cancel := goop.BindMember(order, "total", func(this goop.Object) interface{} {
+		return this.Get("subtotal").(int) + 5
+	}, "subtotal")
+	if result := order.Get("total"); result != 25 {
+		t.Fatalf("Expected %d but saw %v", 25, result)
+	}
+	order.Set("quantity", 3)
+	if result := order.Get("subtotal"); result != 30 {
+		t.Fatalf("Expected %d but saw %v", 30, result)
+	}
+	if result := order.Get("total"); result != 35 {
+		t.Fatalf("Expected %d but saw %v", 35, result)
+	}
+	order.Add("price", 1)
+	if result := order.Get("total"); result != 38 {
+		t.Fatalf("Expected %d but saw %v", 38, result)
+	}
+
+	// A canceled binding keeps its last value.
+	cancel()
+	order.Set("price", 100)
+	if result := order.Get("subtotal"); result != 300 {
+		t.Fatalf("Expected %d but saw %v", 300, result)
+	}
+	if result := order.Get("total"); result != 38 {
+		t.Fatalf("Expected %d but saw %v", 38, result)
+	}
+}
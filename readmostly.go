@@ -0,0 +1,71 @@
+// This file implements read-mostly objects, whose members can be read
+// without locking.
+
+package goop
+
+// A readView is an immutable copy of an object's members and
+// prototypes that can be read without holding the object's lock.
+type readView struct {
+	table      memberTable // Object's symbol table, shared copy-on-write with the object
+	prototypes []Object    // Object's prototypes
+	gen        uint64      // Object's protoGen
+}
+
+// EnableReadMostly optimizes the object for being read far more often
+// than it is modified, as is typical of an object whose methods are
+// set up once and then merely invoked.  In read-mostly mode, Get,
+// GetOK, GetSym, Call, and the like read the object's members from an
+// immutable copy without acquiring the object's lock, so concurrent
+// readers never contend with each other.  Modifying the object
+// discards the copy, which is recreated (under the object's lock) by
+// the next read, so each modification costs a copy of the object's
+// members.
+func (obj *Object) EnableReadMostly() {
+	obj.Implementation.readMostly.Store(true)
+}
+
+// DisableReadMostly returns the object to ordinary locked reads,
+// which is preferable for objects that are modified frequently.
+func (obj *Object) DisableReadMostly() {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	impl.readMostly.Store(false)
+	impl.view.Store(nil)
+	impl.lock.Unlock()
+}
+
+// readView returns the object's current readView, creating one if
+// necessary.  It returns nil if the object is not in read-mostly mode.
+func (impl *internal) readView() *readView {
+	if !impl.readMostly.Load() {
+		return nil
+	}
+	if view := impl.view.Load(); view != nil {
+		return view
+	}
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	if !impl.readMostly.Load() {
+		return nil
+	}
+	if view := impl.view.Load(); view != nil {
+		return view
+	}
+	impl.tableShared = true
+	view := &readView{
+		table:      impl.symbolTable,
+		prototypes: impl.prototypes,
+		gen:        impl.protoGen,
+	}
+	impl.view.Store(view)
+	return view
+}
+
+// discardView discards the object's readView, if any, so that
+// subsequent reads observe a change the caller is making.  The caller
+// must hold the object's lock.
+func (impl *internal) discardView() {
+	if impl.view.Load() != nil {
+		impl.view.Store(nil)
+	}
+}
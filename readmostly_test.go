@@ -0,0 +1,105 @@
+// This file tests read-mostly objects.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"sync"
+	"testing"
+)
+
+// Test that reads of a read-mostly object observe modifications.
+func TestReadMostly(t *testing.T) {
+	proto := goop.New()
+	proto.Set("greet", func(this goop.Object) string { return "Hello" })
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("x", 1)
+	obj.EnableReadMostly()
+	if result := obj.Get("x"); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	if result := obj.Call("greet")[0]; result != "Hello" {
+		t.Fatalf("Expected %q but saw %v", "Hello", result)
+	}
+	snap := obj.Snapshot()
+	obj.Set("x", 2)
+	obj.Set("y", 3)
+	if result := obj.Get("x"); result != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, result)
+	}
+	if result := obj.GetSym(goop.Intern("y")); result != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, result)
+	}
+	obj.SetSym(goop.Intern("x"), 4)
+	if result := obj.Get("x"); result != 4 {
+		t.Fatalf("Expected %d but saw %v", 4, result)
+	}
+	obj.Unset("y")
+	if result := obj.Get("y"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	obj.SetSuper()
+	if result := obj.Get("greet"); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	obj.Restore(snap)
+	if result := obj.Get("x"); result != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, result)
+	}
+	obj.DisableReadMostly()
+	obj.Set("x", 5)
+	if result := obj.Get("x"); result != 5 {
+		t.Fatalf("Expected %d but saw %v", 5, result)
+	}
+}
+
+// Test concurrent reads and writes of a read-mostly object.
+func TestReadMostlyConcurrent(t *testing.T) {
+	obj := goop.New()
+	obj.Set("count", 0)
+	obj.EnableReadMostly()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev := 0
+			for j := 0; j < 1000; j++ {
+				count := obj.Get("count").(int)
+				if count < prev {
+					t.Errorf("Expected at least %d but saw %d", prev, count)
+					return
+				}
+				prev = count
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		obj.Set("count", i)
+	}
+	wg.Wait()
+}
+
+// Benchmark concurrent method calls on a read-mostly object.
+func BenchmarkReadMostlyCall(b *testing.B) {
+	obj := goop.New()
+	obj.Set("answer", func(this goop.Object) int { return 42 })
+	obj.EnableReadMostly()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			obj.Call("answer")
+		}
+	})
+}
+
+// Benchmark concurrent method calls on an ordinary object.
+func BenchmarkLockedCall(b *testing.B) {
+	obj := goop.New()
+	obj.Set("answer", func(this goop.Object) int { return 42 })
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			obj.Call("answer")
+		}
+	})
+}
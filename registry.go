@@ -0,0 +1,87 @@
+// This file implements a registry that associates names with
+// prototype objects.
+
+package goop
+
+import "sort"
+import "sync"
+
+// Constructor names the method that New invokes on an object created
+// from a registered prototype.  The method is passed New's remaining
+// arguments.
+const Constructor = "constructor"
+
+// registry maps a name to a prototype object.
+var registry = map[string]Object{}
+
+// registryLock protects registry.
+var registryLock sync.RWMutex
+
+// Register associates a name with a prototype object so that the
+// prototype can be retrieved with Lookup and instantiated with New.
+// Registering a name that is already registered replaces the previous
+// prototype.
+func Register(name string, proto Object) {
+	registryLock.Lock()
+	registry[name] = proto
+	registryLock.Unlock()
+}
+
+// Unregister removes a name from the registry.  It returns true if the
+// name was registered.
+func Unregister(name string) bool {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	_, ok := registry[name]
+	delete(registry, name)
+	return ok
+}
+
+// Lookup returns the prototype registered under a given name and true,
+// or an empty Object and false if no prototype is registered under
+// that name.
+func Lookup(name string) (Object, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	proto, ok := registry[name]
+	return proto, ok
+}
+
+// RegisteredNames returns the names of all registered prototypes in
+// lexical order.
+func RegisteredNames() []string {
+	registryLock.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryLock.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// constructRegistered implements New for the name of a registered
+// prototype.  It makes the registered prototype the prototype of a
+// newly allocated object and invokes the object's Constructor method,
+// if any, on the given arguments.  constructRegistered panics with a
+// *NotFoundError if the name is not registered or if arguments are
+// given but there is no Constructor method, and it panics with the
+// error CallErr returns, such as an *ArgumentError or a *PanicError,
+// if the Constructor method fails.
+func constructRegistered(obj Object, name string, arguments []interface{}) Object {
+	proto, ok := Lookup(name)
+	if !ok {
+		panic(&NotFoundError{Member: name})
+	}
+	markPrototypes([]Object{proto})
+	obj.Implementation.prototypes = []Object{proto}
+	switch {
+	case obj.HasMember(Constructor):
+		if _, err := obj.CallErr(Constructor, arguments...); err != nil {
+			panic(err)
+		}
+	case len(arguments) > 0:
+		panic(&NotFoundError{Member: Constructor, Method: true})
+	}
+	return obj
+}
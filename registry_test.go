@@ -0,0 +1,76 @@
+// This file tests the registry of named prototypes.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test registering a prototype and instantiating it by name.
+func TestRegister(t *testing.T) {
+	// Register a prototype with a constructor.
+	point := goop.New()
+	point.Set(goop.Constructor, func(this goop.Object, x, y, z float64) {
+		this.Set("x", x)
+		this.Set("y", y)
+		this.Set("z", z)
+	})
+	point.Set("sum", func(this goop.Object) float64 {
+		return this.Get("x").(float64) + this.Get("y").(float64) + this.Get("z").(float64)
+	})
+	goop.Register("Point3D", point)
+	defer goop.Unregister("Point3D")
+	if proto, ok := goop.Lookup("Point3D"); !ok || !proto.IsEquiv(point) {
+		t.Fatalf("Expected to look up %q", "Point3D")
+	}
+
+	// Ensure that New instantiates the prototype.
+	p := goop.New("Point3D", 1.0, 2.0, 3.0)
+	if !p.IsA(point) {
+		t.Fatalf("Expected the new object to inherit from the prototype")
+	}
+	if result := p.Call("sum")[0].(float64); result != 6.0 {
+		t.Fatalf("Expected %.1f but saw %v", 6.0, result)
+	}
+
+	// Ensure that an unknown name is rejected.
+	if _, ok := goop.Lookup("Point4D"); ok {
+		t.Fatalf("Unexpectedly looked up %q", "Point4D")
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected New to panic on an unregistered name")
+		}
+	}()
+	goop.New("Point4D")
+}
+
+// Test that New panics if a registered prototype's constructor fails.
+func TestRegisterConstructorFails(t *testing.T) {
+	proto := goop.New()
+	proto.Set(goop.Constructor, func(this goop.Object, n int) {
+		if n < 0 {
+			panic(goop.ErrInvalid)
+		}
+		this.Set("n", n)
+	})
+	goop.Register("Natural", proto)
+	defer goop.Unregister("Natural")
+	for _, arg := range []interface{}{-1, "one"} {
+		func() {
+			defer func() {
+				r := recover()
+				var expected error = goop.ErrInvalid
+				if arg == "one" {
+					expected = goop.ErrBadArguments
+				}
+				if err, ok := r.(error); !ok || !errors.Is(err, expected) {
+					t.Fatalf("Expected %v but saw %v", expected, r)
+				}
+			}()
+			goop.New("Natural", arg)
+		}()
+	}
+}
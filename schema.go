@@ -0,0 +1,133 @@
+// This file implements schemas, which describe the members an object
+// is expected to contain.
+
+package goop
+
+import "errors"
+import "fmt"
+import "reflect"
+
+// ErrInvalid is returned when a member's value is rejected by a
+// Field's Check function.
+var ErrInvalid = errors.New("Invalid member value")
+
+// A Field describes a member of an object that conforms to a Schema.
+type Field struct {
+	Name     string                        // Name of the member
+	Type     reflect.Type                  // Type to which the member's value must be assignable, or nil for any type
+	Required bool                          // true if the member must exist
+	Check    func(value interface{}) error // Function that returns an error if the member's value is invalid, or nil
+}
+
+// A Schema describes the members an object is expected to contain.
+// Members not described by the Schema are unconstrained.
+type Schema []Field
+
+// A ValidationError describes a member whose value was rejected by a
+// Field's Check function.  It wraps the error Check returned.
+// errors.Is additionally reports that a ValidationError is ErrInvalid.
+type ValidationError struct {
+	Member string // Name of the member
+	Err    error  // Error returned by Check
+}
+
+// Error returns a description of a ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Member %q: %s: %v", e.Member, ErrInvalid, e.Err)
+}
+
+// Unwrap returns the error returned by Check.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if the target is ErrInvalid.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// field returns the Field describing the named member or nil if the
+// Schema does not describe the member.
+func (s Schema) field(memberName string) *Field {
+	for i := range s {
+		if s[i].Name == memberName {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// check returns an error if a value is not valid for the Field: a
+// *TypeError if the value is not assignable to the Field's Type and a
+// *ValidationError if the Field's Check function rejects the value.
+func (f *Field) check(value interface{}) error {
+	if f.Type != nil {
+		actual := reflect.TypeOf(value)
+		if actual == nil && !acceptsNil(f.Type) || actual != nil && !actual.AssignableTo(f.Type) {
+			return &TypeError{Member: f.Name, Expected: f.Type, Actual: actual}
+		}
+	}
+	if f.Check != nil {
+		if err := f.Check(value); err != nil {
+			return &ValidationError{Member: f.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// Validate checks the object's members, including those it inherits,
+// against a Schema and returns an error for each Field to which the
+// object does not conform, in the order of the Schema's Fields.  The
+// errors are a *NotFoundError for each missing Required member, a
+// *TypeError for each member whose value is not assignable to the
+// Field's Type, and a *ValidationError for each member whose value the
+// Field's Check function rejects.  Validate returns nil if the object
+// conforms to the Schema.
+func (obj *Object) Validate(schema Schema) []error {
+	var errs []error
+	for i := range schema {
+		f := &schema[i]
+		value, ok := obj.GetOK(f.Name)
+		if !ok {
+			if f.Required {
+				errs = append(errs, &NotFoundError{Member: f.Name})
+			}
+			continue
+		}
+		if err := f.check(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Enforce makes subsequent assignments to the object's members by Set,
+// TrySet, and SetSym fail (panicking or returning an error, as each
+// does for other failures) with the error Validate would report for a
+// value that does not conform to a Schema.  Enforce does not check the
+// object's existing members; call Validate to do so.  Nor does it
+// check sums stored by Add, which preserves a member's type but not
+// necessarily its validity.  The Schema applies only to the object
+// itself, not to its descendants, and replaces any Schema previously
+// enforced on the object.  Passing nil stops enforcement.
+func (obj *Object) Enforce(schema Schema) {
+	if schema == nil {
+		obj.Implementation.schema.Store(nil)
+		return
+	}
+	schema = append(Schema(nil), schema...)
+	obj.Implementation.schema.Store(&schema)
+}
+
+// checkSchema returns an error if a value may not be assigned to the
+// named member because of a Schema enforced on the object.
+func (impl *internal) checkSchema(memberName string, value interface{}) error {
+	schema := impl.schema.Load()
+	if schema == nil {
+		return nil
+	}
+	if f := schema.field(memberName); f != nil {
+		return f.check(value)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// This file tests schemas.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"reflect"
+	"testing"
+)
+
+// userSchema describes a user record for the tests that follow.
+var userSchema = goop.Schema{
+	{Name: "name", Type: reflect.TypeOf(""), Required: true},
+	{Name: "age", Type: reflect.TypeOf(0), Check: func(value interface{}) error {
+		if value.(int) < 0 {
+			return errors.New("negative age")
+		}
+		return nil
+	}},
+	{Name: "email", Type: reflect.TypeOf(""), Required: true},
+}
+
+// Test validating an object's members against a schema.
+func TestValidate(t *testing.T) {
+	proto := goop.New()
+	proto.Set("name", "anonymous")
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("age", -1)
+	errs := obj.Validate(userSchema)
+	if len(errs) != 2 {
+		t.Fatalf("Expected %d but saw %v", 2, errs)
+	}
+	if !errors.Is(errs[0], goop.ErrInvalid) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrInvalid, errs[0])
+	}
+	var nfErr *goop.NotFoundError
+	if !errors.As(errs[1], &nfErr) || nfErr.Member != "email" {
+		t.Fatalf("Expected %q but saw %v", "email", errs[1])
+	}
+	obj.Set("age", "old")
+	obj.Set("email", "user@example.com")
+	errs = obj.Validate(userSchema)
+	if len(errs) != 1 || !errors.Is(errs[0], goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, errs)
+	}
+	obj.Set("age", 30)
+	if errs = obj.Validate(userSchema); errs != nil {
+		t.Fatalf("Expected %v but saw %v", nil, errs)
+	}
+}
+
+// Test rejecting assignments that do not conform to a schema.
+func TestEnforce(t *testing.T) {
+	obj := goop.New()
+	obj.Enforce(userSchema)
+	if err := obj.TrySet("age", 1.5); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+	if err := obj.TrySet("age", -5); !errors.Is(err, goop.ErrInvalid) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrInvalid, err)
+	}
+	if obj.HasMember("age") {
+		t.Fatalf("Expected %v but saw %v", false, true)
+	}
+	obj.Set("age", 5)
+	obj.Set("other", 1.5)
+	age := goop.Intern("age")
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatalf("Expected a panic")
+			}
+		}()
+		obj.SetSym(age, -6)
+	}()
+	if result := obj.Get("age"); result != 5 {
+		t.Fatalf("Expected %d but saw %v", 5, result)
+	}
+
+	// Stop enforcing the schema.
+	obj.Enforce(nil)
+	obj.SetSym(age, -6)
+	if result := obj.Get("age"); result != -6 {
+		t.Fatalf("Expected %d but saw %v", -6, result)
+	}
+}
@@ -0,0 +1,101 @@
+// This file implements shared members, which descendants of an object
+// update in place rather than override.
+
+package goop
+
+import "sync/atomic"
+
+// SetShared is like Set but additionally marks the member as shared.
+// Setting (or Adding to) a shared member via any descendant of the
+// object that does not itself contain the member modifies the
+// object's member instead of creating a new member in the descendant.
+// All descendants therefore observe the same value, as with a static
+// member of a class.  Unsetting the member on the object also removes
+// its shared status.
+func (obj *Object) SetShared(memberName string, value interface{}) {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	if err := impl.checkDefine(memberName); err != nil {
+		impl.lock.Unlock()
+		panic(err)
+	}
+	impl.markShared(memberName)
+	impl.lock.Unlock()
+	if err := obj.TrySet(memberName, value); err != nil {
+		panic(err)
+	}
+}
+
+// anyShared is true once any member of any object has been marked as
+// shared.  It lets Set skip searching prototypes for a shared member
+// in programs that never call SetShared.
+var anyShared atomic.Bool
+
+// markShared marks the named member as shared.  The caller must hold
+// the object's lock.
+func (impl *internal) markShared(memberName string) {
+	if impl.shared == nil {
+		impl.shared = make(map[string]bool)
+	}
+	impl.shared[memberName] = true
+	anyShared.Store(true)
+}
+
+// sharedOwner returns the ancestor that provides the named member and
+// true if the member is shared and not overridden by the object
+// itself.  Otherwise, it returns false.
+func (obj *Object) sharedOwner(memberName string) (Object, bool) {
+	if !anyShared.Load() {
+		return Object{}, false
+	}
+	impl := obj.Implementation
+	impl.lock.RLock()
+	defer impl.lock.RUnlock()
+	if _, isLocal := impl.symbolTable.get(memberName); isLocal {
+		return Object{}, false
+	}
+	return impl.sharedAncestor(memberName)
+}
+
+// sharedAncestor returns the ancestor that provides the named member
+// and true if the member is shared and the object, which does not
+// itself contain the member, has not marked it as shared.  Otherwise,
+// it returns false.  The caller must hold the object's lock.
+func (impl *internal) sharedAncestor(memberName string) (Object, bool) {
+	if !anyShared.Load() || impl.shared[memberName] {
+		return Object{}, false
+	}
+	for _, parent := range impl.prototypes {
+		owner, found := parent.owner(memberName)
+		if !found {
+			continue
+		}
+		oImpl := owner.Implementation
+		oImpl.lock.RLock()
+		isShared := oImpl.shared[memberName]
+		oImpl.lock.RUnlock()
+		return owner, isShared
+	}
+	return Object{}, false
+}
+
+// owner returns the object that provides the named member, which is
+// either the object itself or the first ancestor containing the member
+// in the order in which Get searches, and true.  It returns false if
+// the member does not exist.
+func (obj *Object) owner(memberName string) (Object, bool) {
+	impl := obj.Implementation
+	impl.lock.RLock()
+	_, found := impl.symbolTable.get(memberName)
+	prototypes := impl.prototypes
+	impl.lock.RUnlock()
+	if found {
+		return *obj, true
+	}
+	for _, parent := range prototypes {
+		if owner, found := parent.owner(memberName); found {
+			return owner, true
+		}
+	}
+	return Object{}, false
+}
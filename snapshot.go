@@ -0,0 +1,162 @@
+// This file implements snapshots, which capture an object's members so
+// they can later be restored.
+
+package goop
+
+import "errors"
+
+// ErrForeignSnapshot is returned by an attempt to restore an object
+// from a snapshot of a different object.
+var ErrForeignSnapshot = errors.New("Snapshot was taken of a different object")
+
+// A Snapshot records the state of an object's own members at a point
+// in time, as captured by Snapshot or SnapshotWithPrototypes.
+type Snapshot struct {
+	impl       *internal              // Object the snapshot was taken of
+	table      memberTable            // Object's symbol table, shared copy-on-write with the object
+	stored     map[string]interface{} // Values stored in the object's properties and struct fields
+	dependents map[string][]*computed // Object's computed-member dependencies
+	prototypes []Object               // Object's prototypes if captured, or nil
+	withProtos bool                   // true if prototypes were captured
+}
+
+// Snapshot captures the object's own members so that Restore can later
+// return the object to its current state.  Taking a snapshot is cheap:
+// the object's members are not copied until the object is next
+// modified, and member values are never copied, so changes made within
+// a member's value (e.g., to a slice's elements or to a nested object)
+// are not undone by Restore.  Inherited members are not captured.
+func (obj *Object) Snapshot() *Snapshot {
+	return obj.snapshot(false)
+}
+
+// SnapshotWithPrototypes is like Snapshot but additionally captures
+// the object's list of prototypes.
+func (obj *Object) SnapshotWithPrototypes() *Snapshot {
+	return obj.snapshot(true)
+}
+
+// snapshot implements Snapshot and SnapshotWithPrototypes.
+func (obj *Object) snapshot(withProtos bool) *Snapshot {
+	impl := obj.Implementation
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	return impl.capture(withProtos)
+}
+
+// capture returns a Snapshot of the object.  The caller must hold the
+// object's lock.
+func (impl *internal) capture(withProtos bool) *Snapshot {
+	impl.tableShared = true
+	snap := &Snapshot{
+		impl:       impl,
+		table:      impl.symbolTable,
+		dependents: copyDependents(impl.dependents),
+		withProtos: withProtos,
+	}
+	if withProtos {
+		snap.prototypes = impl.prototypes
+	}
+	impl.symbolTable.each(func(name string, member interface{}) {
+		if sv, ok := member.(storedValue); ok {
+			if snap.stored == nil {
+				snap.stored = make(map[string]interface{})
+			}
+			snap.stored[name] = sv.get()
+		}
+	})
+	return snap
+}
+
+// Restore returns the object's own members (and, for a snapshot taken
+// by SnapshotWithPrototypes, its list of prototypes) to their state at
+// the time a snapshot was taken.  Computed members are recomputed on
+// their next access.  Watchers are not notified.  A snapshot may be
+// restored any number of times.  Restore returns ErrForeignSnapshot if
+// the snapshot was taken of a different object, ErrFrozen if the
+// object is frozen, and ErrSealed if the object is sealed and the
+// snapshot's members differ from the object's.  Restoring prototypes
+// that would introduce a cycle returns ErrCycle.
+func (obj *Object) Restore(snap *Snapshot) error {
+	impl := obj.Implementation
+	if snap.impl != impl {
+		return ErrForeignSnapshot
+	}
+	if snap.withProtos && obj.introducesCycle(snap.prototypes) {
+		return ErrCycle
+	}
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	return impl.restore(snap)
+}
+
+// restore implements Restore after the snapshot's origin and
+// prototypes have been checked.  The caller must hold the object's
+// lock.
+func (impl *internal) restore(snap *Snapshot) error {
+	switch {
+	case impl.frozen:
+		return ErrFrozen
+	case impl.sealed && !sameKeys(&impl.symbolTable, &snap.table):
+		return ErrSealed
+	}
+	impl.discardView()
+	impl.symbolTable = snap.table
+	impl.tableShared = true
+	impl.dependents = copyDependents(snap.dependents)
+	if snap.withProtos {
+		impl.prototypes = snap.prototypes
+		impl.prototypesChanged()
+	} else {
+		impl.invalidateLookups()
+	}
+	for name, value := range snap.stored {
+		member, _ := snap.table.get(name)
+		member.(storedValue).set(value)
+	}
+	snap.table.each(func(name string, member interface{}) {
+		if c, ok := member.(*computed); ok {
+			c.invalidate()
+		}
+	})
+	return nil
+}
+
+// ownTable ensures that the object's symbol table is not shared with
+// a Snapshot, copying it if necessary.  The caller must hold the
+// object's lock and must call ownTable before modifying the symbol
+// table.
+func (impl *internal) ownTable() {
+	if !impl.tableShared {
+		return
+	}
+	impl.discardView()
+	impl.symbolTable = impl.symbolTable.copy()
+	impl.tableShared = false
+}
+
+// copyDependents returns a deep copy of a map from member names to
+// the computed members that depend on them.
+func copyDependents(dependents map[string][]*computed) map[string][]*computed {
+	if dependents == nil {
+		return nil
+	}
+	result := make(map[string][]*computed, len(dependents))
+	for name, cs := range dependents {
+		result[name] = append([]*computed(nil), cs...)
+	}
+	return result
+}
+
+// sameKeys returns true if two symbol tables contain the same member
+// names.
+func sameKeys(a, b *memberTable) bool {
+	if a.size() != b.size() {
+		return false
+	}
+	same := true
+	a.each(func(name string, _ interface{}) {
+		same = same && b.has(name)
+	})
+	return same
+}
@@ -0,0 +1,56 @@
+// This file tests capturing and restoring object state.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test restoring an object's members from a snapshot.
+func TestSnapshot(t *testing.T) {
+	// Take a snapshot of an object with plain, computed, and
+	// property members, then modify it.
+	obj := goop.New()
+	obj.Set("x", 1)
+	obj.DefineProperty("y", goop.Descriptor{Enumerable: true})
+	obj.Set("y", 2)
+	obj.DefineComputed("sum", []string{"x", "y"}, func(this goop.Object) interface{} {
+		return this.Get("x").(int) + this.Get("y").(int)
+	})
+	snap := obj.Snapshot()
+	obj.Set("x", 10)
+	obj.Set("y", 20)
+	obj.Set("z", 30)
+	if result := obj.Get("sum").(int); result != 30 {
+		t.Fatalf("Expected %d but saw %v", 30, result)
+	}
+
+	// Ensure that restoring the snapshot undoes the modifications,
+	// and that it can be restored repeatedly.
+	for i := 0; i < 2; i++ {
+		if err := obj.Restore(snap); err != nil {
+			t.Fatalf("Expected %v but saw %v", nil, err)
+		}
+		if obj.HasMember("z") {
+			t.Fatalf("Unexpectedly found member %q", "z")
+		}
+		if result := obj.Get("sum").(int); result != 3 {
+			t.Fatalf("Expected %d but saw %v", 3, result)
+		}
+		obj.Set("x", 100)
+	}
+
+	// Ensure that prototypes are restored only when captured and
+	// that snapshots cannot be applied to other objects.
+	parent := goop.New()
+	snap = obj.SnapshotWithPrototypes()
+	obj.SetSuper(parent)
+	obj.Restore(snap)
+	if len(obj.Super()) != 0 {
+		t.Fatalf("Expected %d prototypes but saw %d", 0, len(obj.Super()))
+	}
+	if err := parent.Restore(snap); err != goop.ErrForeignSnapshot {
+		t.Fatalf("Expected %v but saw %v", goop.ErrForeignSnapshot, err)
+	}
+}
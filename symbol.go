@@ -0,0 +1,146 @@
+// This file implements interned member names, which provide faster
+// access to members than do strings.
+
+package goop
+
+import "sync"
+
+// A Symbol is an interned member name, as returned by Intern.  Accessing
+// a member via a Symbol (see GetSym, SetSym, and CallSym) compares small
+// integers rather than hashing the member's name, which benefits code
+// that accesses the same members many times.  A Symbol is valid only if
+// returned by Intern.
+type Symbol struct {
+	id   uint32 // Unique identifier of the name, starting from 1
+	name string // Member name
+}
+
+// symbols is the table of all interned member names.
+var symbols struct {
+	lock sync.RWMutex      // Lock protecting ids
+	ids  map[string]uint32 // Map from a member name to its identifier
+}
+
+// Intern returns the Symbol corresponding to a member name.  Interning
+// the same name always returns the same Symbol.  Because interned names
+// are never forgotten, Intern is meant for a program's fixed set of
+// member names, typically assigned to package-level variables, not for
+// arbitrary names computed at run time.
+func Intern(name string) Symbol {
+	symbols.lock.RLock()
+	id, ok := symbols.ids[name]
+	symbols.lock.RUnlock()
+	if ok {
+		return Symbol{id: id, name: name}
+	}
+	symbols.lock.Lock()
+	defer symbols.lock.Unlock()
+	if id, ok = symbols.ids[name]; !ok {
+		if symbols.ids == nil {
+			symbols.ids = make(map[string]uint32)
+		}
+		id = uint32(len(symbols.ids) + 1)
+		symbols.ids[name] = id
+	}
+	return Symbol{id: id, name: name}
+}
+
+// String returns the member name a Symbol represents.
+func (sym Symbol) String() string {
+	return sym.name
+}
+
+// GetSym is like Get but identifies the member by a Symbol.
+func (obj *Object) GetSym(sym Symbol) interface{} {
+	value, ok := obj.getSymOK(sym)
+	if !ok {
+		return ErrNotFound
+	}
+	return value
+}
+
+// getSymOK is like GetOK but identifies the member by a Symbol.
+func (obj *Object) getSymOK(sym Symbol) (interface{}, bool) {
+	if m := activeMetrics(); m != nil {
+		m.MemberRead(obj.memberDepth(sym.name))
+	}
+	impl := obj.Implementation
+	if view := impl.readView(); view != nil {
+		if value, ok := view.table.getSym(sym); ok {
+			return memberValue(*obj, value), true
+		}
+		return impl.lookupInherited(*obj, view.prototypes, view.gen, sym.name)
+	}
+	impl.lock.RLock()
+	value, ok := impl.symbolTable.getSym(sym)
+	prototypes := impl.prototypes
+	gen := impl.protoGen
+	impl.lock.RUnlock()
+	if ok {
+		return memberValue(*obj, value), true
+	}
+	return impl.lookupInherited(*obj, prototypes, gen, sym.name)
+}
+
+// SetSym is like Set but identifies the member by a Symbol.  Assigning
+// a new value to an ordinary member the object already contains is
+// faster than with Set.
+func (obj *Object) SetSym(sym Symbol, value interface{}) {
+	if old, watchers, ok := obj.Implementation.setSym(sym, value); ok {
+		if m := activeMetrics(); m != nil {
+			m.MemberWritten()
+		}
+		notifyWatchers(watchers, old, value)
+		return
+	}
+	obj.Set(sym.name, value)
+}
+
+// setSym replaces the value of an existing ordinary member and returns
+// the member's previous value, the watchers to notify of the change,
+// and true.  It returns false without modifying the object if the
+// member must instead be assigned by Set, such as when the member does
+// not exist, is computed, or is a property, or when the object is
+// frozen, records history, or enforces a Schema.
+func (impl *internal) setSym(sym Symbol, value interface{}) (interface{}, []*watcher, bool) {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	table := &impl.symbolTable
+	if impl.frozen || impl.history != nil || impl.tableShared || table.shape == nil || impl.schema.Load() != nil {
+		return nil, nil, false
+	}
+	for i, id := range table.shape.symbols {
+		if id != sym.id {
+			continue
+		}
+		old := table.values[i]
+		switch old.(type) {
+		case *computed, *property, *structField:
+			return nil, nil, false
+		}
+		table.values[i] = value
+		impl.memberChanged(sym.name)
+		return old, impl.watchers[sym.name], true
+	}
+	return nil, nil, false
+}
+
+// CallSym is like Call but identifies the method by a Symbol.
+func (obj *Object) CallSym(sym Symbol, arguments ...interface{}) []interface{} {
+	if m := activeMetrics(); m != nil {
+		m.MethodCalled()
+	}
+	if hooks := obj.hooksFor(sym.name); hooks != nil {
+		return runHooks(*obj, hooks, arguments, func(argList []interface{}) []interface{} {
+			return obj.call(sym.name, argList)
+		})
+	}
+	if obj.tracer() != nil {
+		return obj.call(sym.name, arguments)
+	}
+	userFuncIface, ok := obj.getSymOK(sym)
+	if !ok {
+		return obj.call(sym.name, arguments)
+	}
+	return callResults(obj.callMethod(sym.name, userFuncIface, arguments))
+}
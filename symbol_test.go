@@ -0,0 +1,105 @@
+// This file tests interned member names.
+
+package goop_test
+
+import (
+	"fmt"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test that interning a name always returns the same Symbol.
+func TestIntern(t *testing.T) {
+	a := goop.Intern("interned")
+	b := goop.Intern("interned")
+	if a != b {
+		t.Fatalf("Expected %v but saw %v", a, b)
+	}
+	if a == goop.Intern("other") {
+		t.Fatalf("Expected distinct symbols for distinct names")
+	}
+	if result := a.String(); result != "interned" {
+		t.Fatalf("Expected %q but saw %q", "interned", result)
+	}
+}
+
+// Test getting, setting, and calling members via symbols.
+func TestSymbolAccess(t *testing.T) {
+	x := goop.Intern("x")
+	scale := goop.Intern("scale")
+	missing := goop.Intern("missing")
+	proto := goop.New()
+	proto.Set("scale", func(this goop.Object, factor int) {
+		this.SetSym(x, this.GetSym(x).(int)*factor)
+	})
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.SetSym(x, 3)
+	if result := obj.Get("x"); result != 3 {
+		t.Fatalf("Expected %d but saw %v", 3, result)
+	}
+	obj.CallSym(scale, 5)
+	if result := obj.GetSym(x); result != 15 {
+		t.Fatalf("Expected %d but saw %v", 15, result)
+	}
+	if result := obj.GetSym(missing); result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+	if result := obj.CallSym(missing)[0]; result != goop.ErrNotFound {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, result)
+	}
+
+	// Members in a map rather than a shape must also be found.
+	for i := 0; i < 100; i++ {
+		obj.Set(fmt.Sprintf("m%d", i), i)
+	}
+	obj.SetSym(x, 7)
+	if result := obj.GetSym(x); result != 7 {
+		t.Fatalf("Expected %d but saw %v", 7, result)
+	}
+}
+
+// Test that SetSym honors watchers and frozen objects.
+func TestSetSymSemantics(t *testing.T) {
+	count := goop.Intern("count")
+	obj := goop.New()
+	obj.Set("count", 1)
+	var seen interface{}
+	obj.Watch("count", func(old, new interface{}) {
+		seen = old
+	})
+	obj.SetSym(count, 2)
+	if seen != 1 {
+		t.Fatalf("Expected %d but saw %v", 1, seen)
+	}
+	obj.Freeze()
+	defer func() {
+		if r := recover(); r != goop.ErrFrozen {
+			t.Fatalf("Expected %v but saw %v", goop.ErrFrozen, r)
+		}
+	}()
+	obj.SetSym(count, 3)
+}
+
+// Benchmark getting and setting a member via a Symbol.
+func BenchmarkSymbolAccess(b *testing.B) {
+	x := goop.Intern("x")
+	obj := goop.New()
+	obj.Set("x", 0)
+	obj.Set("y", 0)
+	obj.Set("z", 0)
+	for i := b.N; i > 0; i-- {
+		obj.SetSym(x, obj.GetSym(x).(int)+1)
+	}
+}
+
+// Benchmark getting and setting a member via a string.
+func BenchmarkStringAccess(b *testing.B) {
+	obj := goop.New()
+	obj.Set("x", 0)
+	obj.Set("y", 0)
+	obj.Set("z", 0)
+	for i := b.N; i > 0; i-- {
+		obj.Set("x", obj.Get("x").(int)+1)
+	}
+}
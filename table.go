@@ -0,0 +1,306 @@
+// This file implements the storage of an object's own members.
+
+package goop
+
+import "sync"
+
+// Objects store their members in a memberTable.  To avoid allocating
+// a map per object, a memberTable initially stores its values in a
+// slice whose layout is described by a shape shared by all objects
+// whose members were added in the same order, as is typical of
+// objects created by the same constructor.  A memberTable "diverges"
+// into an ordinary map when a member is removed or when its shape
+// would grow too large or too bushy to be worth sharing.  A map that
+// grows very large is in turn split into shards, each an ordinary map
+// holding the members whose names hash to it.  Sharding bounds the
+// pause incurred when a map grows and must be rehashed, and it lets a
+// copy-on-write copy of the table (see Snapshot and EnableReadMostly)
+// copy only the shard being modified rather than every member.
+
+// maxShapeMembers is the largest number of members described by a
+// shape.  Objects with more members than this store them in a map.
+const maxShapeMembers = 64
+
+// maxShapeTransitions is the largest number of distinct shapes that
+// can be derived from a given shape by adding a member.  Objects that
+// would exceed this store their members in a map.
+const maxShapeTransitions = 32
+
+// maxSymbolScan is the largest number of members for which getSym
+// scans a shape's interned names rather than hashing a member name.
+const maxSymbolScan = 16
+
+// shardThreshold is the number of members beyond which a memberTable
+// splits its map into shards.
+const shardThreshold = 1 << 13
+
+// numShards is the number of shards into which a memberTable splits
+// its map.  It must not exceed the number of bits in a uint64.
+const numShards = 64
+
+// initialTableSize is the number of values for which a memberTable
+// initially allocates space.
+const initialTableSize = 4
+
+// A shape maps member names to indices in a memberTable's slice of
+// values.  A shape is immutable except for its list of transitions.
+type shape struct {
+	slots       map[string]int    // Map from a member name to its index
+	names       []string          // Member names in index order
+	symbols     []uint32          // Interned member-name identifiers in index order
+	lock        sync.Mutex        // Lock protecting transitions
+	transitions map[string]*shape // Map from an added member name to the resulting shape
+}
+
+// rootShape is the shape of an object with no members.
+var rootShape = &shape{slots: map[string]int{}}
+
+// withMember returns the shape that results from adding a member to
+// a shape, or nil if that shape would exceed the limits on shape size
+// and sharing.
+func (s *shape) withMember(name string) *shape {
+	if len(s.names) >= maxShapeMembers {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if next, ok := s.transitions[name]; ok {
+		return next
+	}
+	if len(s.transitions) >= maxShapeTransitions {
+		return nil
+	}
+	next := &shape{
+		slots:   make(map[string]int, len(s.slots)+1),
+		names:   make([]string, len(s.names), len(s.names)+1),
+		symbols: make([]uint32, len(s.symbols), len(s.symbols)+1),
+	}
+	for n, i := range s.slots {
+		next.slots[n] = i
+	}
+	next.slots[name] = len(s.names)
+	copy(next.names, s.names)
+	next.names = append(next.names, name)
+	copy(next.symbols, s.symbols)
+	next.symbols = append(next.symbols, Intern(name).id)
+	if s.transitions == nil {
+		s.transitions = make(map[string]*shape)
+	}
+	s.transitions[name] = next
+	return next
+}
+
+// A memberTable maps an object's member names to member values.  It
+// uses either a shape and a slice of values or, once diverged, a map.
+type memberTable struct {
+	shape  *shape                   // Layout of values, or nil if diverged
+	values []interface{}            // Member values in the order given by shape
+	dict   map[string]interface{}   // Map from a member name to a member value if diverged and not sharded
+	shards []map[string]interface{} // Maps from a member name to a member value if sharded
+	owned  uint64                   // Set of shards that are not shared with a copy of the table
+	count  int                      // Number of members if sharded
+}
+
+// newMemberTable returns an empty memberTable.
+func newMemberTable() memberTable {
+	return memberTable{shape: rootShape}
+}
+
+// get returns the value of a member and true, or nil and false if the
+// table contains no such member.
+func (t *memberTable) get(name string) (interface{}, bool) {
+	if t.shards != nil {
+		value, ok := t.shards[shardIndex(name)][name]
+		return value, ok
+	}
+	if t.shape == nil {
+		value, ok := t.dict[name]
+		return value, ok
+	}
+	i, ok := t.shape.slots[name]
+	if !ok {
+		return nil, false
+	}
+	return t.values[i], true
+}
+
+// getSym is like get but locates the member by its interned name.  For
+// small shapes, a linear scan of integers avoids hashing the name.
+func (t *memberTable) getSym(sym Symbol) (interface{}, bool) {
+	if t.shape == nil || len(t.shape.symbols) > maxSymbolScan {
+		return t.get(sym.name)
+	}
+	for i, id := range t.shape.symbols {
+		if id == sym.id {
+			return t.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// has returns true if the table contains a member.
+func (t *memberTable) has(name string) bool {
+	_, ok := t.get(name)
+	return ok
+}
+
+// put assigns a value to a member, adding the member if necessary.
+func (t *memberTable) put(name string, value interface{}) {
+	if t.shards != nil {
+		shard := t.ownShard(shardIndex(name))
+		if _, ok := shard[name]; !ok {
+			t.count++
+		}
+		shard[name] = value
+		return
+	}
+	if t.shape == nil {
+		t.dict[name] = value
+		if len(t.dict) > shardThreshold {
+			t.shard()
+		}
+		return
+	}
+	if i, ok := t.shape.slots[name]; ok {
+		t.values[i] = value
+		return
+	}
+	next := t.shape.withMember(name)
+	if next == nil {
+		t.diverge()
+		t.dict[name] = value
+		return
+	}
+	t.shape = next
+	if t.values == nil {
+		t.values = make([]interface{}, 0, initialTableSize)
+	}
+	t.values = append(t.values, value)
+}
+
+// remove deletes a member from the table.
+func (t *memberTable) remove(name string) {
+	if !t.has(name) {
+		return
+	}
+	if t.shards != nil {
+		delete(t.ownShard(shardIndex(name)), name)
+		t.count--
+		return
+	}
+	t.diverge()
+	delete(t.dict, name)
+}
+
+// diverge converts the table from a shape and values to a map.
+func (t *memberTable) diverge() {
+	if t.shape == nil {
+		return
+	}
+	t.dict = make(map[string]interface{}, len(t.values)+1)
+	for i, name := range t.shape.names {
+		t.dict[name] = t.values[i]
+	}
+	t.shape = nil
+	t.values = nil
+}
+
+// shard splits the table's map into shards.
+func (t *memberTable) shard() {
+	t.shards = make([]map[string]interface{}, numShards)
+	for i := range t.shards {
+		t.shards[i] = make(map[string]interface{}, 2*len(t.dict)/numShards)
+	}
+	for name, value := range t.dict {
+		t.shards[shardIndex(name)][name] = value
+	}
+	t.owned = ^uint64(0)
+	t.count = len(t.dict)
+	t.dict = nil
+}
+
+// ownShard returns the shard with a given index, first copying it if
+// it is shared with a copy of the table.
+func (t *memberTable) ownShard(i int) map[string]interface{} {
+	if t.owned&(1<<uint(i)) == 0 {
+		shard := make(map[string]interface{}, len(t.shards[i]))
+		for name, value := range t.shards[i] {
+			shard[name] = value
+		}
+		t.shards[i] = shard
+		t.owned |= 1 << uint(i)
+	}
+	return t.shards[i]
+}
+
+// shardIndex returns the index of the shard that holds a member name,
+// computed with the FNV-1a hash.
+func shardIndex(name string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(name); i++ {
+		h ^= uint32(name[i])
+		h *= 16777619
+	}
+	return int(h % numShards)
+}
+
+// size returns the number of members in the table.
+func (t *memberTable) size() int {
+	if t.shards != nil {
+		return t.count
+	}
+	if t.shape == nil {
+		return len(t.dict)
+	}
+	return len(t.values)
+}
+
+// each invokes a function on each member's name and value.  The
+// function may assign new values to existing members but must not add
+// or remove members.
+func (t *memberTable) each(fn func(name string, value interface{})) {
+	if t.shards != nil {
+		for _, shard := range t.shards {
+			for name, value := range shard {
+				fn(name, value)
+			}
+		}
+		return
+	}
+	if t.shape == nil {
+		for name, value := range t.dict {
+			fn(name, value)
+		}
+		return
+	}
+	for i, name := range t.shape.names {
+		fn(name, t.values[i])
+	}
+}
+
+// copy returns a copy of the table that can be modified independently
+// of the original.
+func (t *memberTable) copy() memberTable {
+	if t.shards != nil {
+		shards := append([]map[string]interface{}(nil), t.shards...)
+		t.owned = 0
+		return memberTable{shards: shards, count: t.count}
+	}
+	if t.shape == nil {
+		dict := make(map[string]interface{}, len(t.dict))
+		for name, value := range t.dict {
+			dict[name] = value
+		}
+		return memberTable{dict: dict}
+	}
+	return memberTable{shape: t.shape, values: append([]interface{}(nil), t.values...)}
+}
+
+// toMap returns a map containing the table's members.
+func (t *memberTable) toMap() map[string]interface{} {
+	result := make(map[string]interface{}, t.size())
+	t.each(func(name string, value interface{}) {
+		result[name] = value
+	})
+	return result
+}
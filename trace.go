@@ -0,0 +1,129 @@
+// This file implements tracing of method invocations.
+
+package goop
+
+import "fmt"
+import "io"
+import "strings"
+import "sync"
+import "sync/atomic"
+import "time"
+
+// A Tracer is notified whenever a method is invoked by Call, CallErr,
+// CallSym, or a function built on them.  A Tracer is installed for all
+// objects with SetTracer or for an individual object with
+// Object.SetTracer.  Its methods may be invoked concurrently from
+// multiple goroutines.  They are invoked inside any hooks registered
+// with AddHook and therefore observe the arguments and results the
+// method itself sees.
+type Tracer interface {
+	// MethodEntered is invoked before a method runs with the ID of
+	// the object on which it is invoked (see Object.ID), the method's
+	// name, and its arguments.
+	MethodEntered(id uint64, methodName string, arguments []interface{})
+
+	// MethodExited is invoked after a method returns (or panics,
+	// in which case results is nil) with the same ID and method name
+	// as the corresponding MethodEntered, the method's results, and
+	// the time the method took to run.
+	MethodExited(id uint64, methodName string, results []interface{}, duration time.Duration)
+}
+
+// tracerBox holds an installed Tracer.
+type tracerBox struct {
+	tracer Tracer
+}
+
+// globalTracer points to the Tracer installed for all objects or is
+// nil if none is installed.
+var globalTracer atomic.Pointer[tracerBox]
+
+// SetTracer installs a Tracer that will be notified of subsequent
+// method invocations on all objects except those with a Tracer of
+// their own.  Passing nil disables tracing, which is the default.
+func SetTracer(t Tracer) {
+	globalTracer.Store(boxTracer(t))
+}
+
+// SetTracer installs a Tracer that will be notified of subsequent
+// method invocations on the object, in place of any Tracer installed
+// by the package-level SetTracer.  Invocations on the object's
+// descendants are not affected.  Passing nil reverts the object to
+// the package-level Tracer.
+func (obj *Object) SetTracer(t Tracer) {
+	obj.Implementation.tracer.Store(boxTracer(t))
+}
+
+// boxTracer returns a tracerBox holding a Tracer or nil if the Tracer
+// is nil.
+func boxTracer(t Tracer) *tracerBox {
+	if t == nil {
+		return nil
+	}
+	return &tracerBox{tracer: t}
+}
+
+// tracer returns the Tracer to notify of method invocations on the
+// object or nil if there is none.
+func (obj *Object) tracer() Tracer {
+	if box := obj.Implementation.tracer.Load(); box != nil {
+		return box.tracer
+	}
+	if box := globalTracer.Load(); box != nil {
+		return box.tracer
+	}
+	return nil
+}
+
+// traceCall invokes a method via a given function, notifying a Tracer
+// of the method's entry and exit.
+func (obj *Object) traceCall(t Tracer, methodName string, arguments []interface{}, call func(string, []interface{}) []interface{}) (results []interface{}) {
+	id := obj.ID()
+	t.MethodEntered(id, methodName, arguments)
+	start := time.Now()
+	defer func() {
+		t.MethodExited(id, methodName, results, time.Since(start))
+	}()
+	return call(methodName, arguments)
+}
+
+// A writerTracer is a Tracer that writes a line of text per event.
+type writerTracer struct {
+	w    io.Writer  // Destination of the trace
+	lock sync.Mutex // Lock serializing writes to w
+}
+
+// NewWriterTracer returns a Tracer that writes a line to an io.Writer
+// when each method is entered and exited, for example:
+//
+//	-> #3 moveBy(1, 2)
+//	<- #3 moveBy = [] (1.5µs)
+//
+// Arguments and results are formatted as with fmt's %v verb.  Writes
+// are serialized, so the Tracer is safe to share among goroutines.
+// Formatting happens before a write begins, so an object whose
+// toString method is traced (see Object.String) can still be
+// formatted.
+func NewWriterTracer(w io.Writer) Tracer {
+	return &writerTracer{w: w}
+}
+
+// MethodEntered writes a line reporting a method's entry.
+func (wt *writerTracer) MethodEntered(id uint64, methodName string, arguments []interface{}) {
+	args := make([]string, len(arguments))
+	for i, arg := range arguments {
+		args[i] = fmt.Sprint(arg)
+	}
+	line := fmt.Sprintf("-> #%d %s(%s)\n", id, methodName, strings.Join(args, ", "))
+	wt.lock.Lock()
+	io.WriteString(wt.w, line)
+	wt.lock.Unlock()
+}
+
+// MethodExited writes a line reporting a method's exit.
+func (wt *writerTracer) MethodExited(id uint64, methodName string, results []interface{}, duration time.Duration) {
+	line := fmt.Sprintf("<- #%d %s = %v (%v)\n", id, methodName, results, duration)
+	wt.lock.Lock()
+	io.WriteString(wt.w, line)
+	wt.lock.Unlock()
+}
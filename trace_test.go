@@ -0,0 +1,101 @@
+// This file tests tracing of method invocations.
+
+package goop_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lanl/goop"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A recordingTracer is a goop.Tracer that records each event as a
+// string.
+type recordingTracer struct {
+	events []string
+}
+
+func (rt *recordingTracer) MethodEntered(id uint64, methodName string, arguments []interface{}) {
+	rt.events = append(rt.events, fmt.Sprintf("enter %d %s %v", id, methodName, arguments))
+}
+
+func (rt *recordingTracer) MethodExited(id uint64, methodName string, results []interface{}, duration time.Duration) {
+	rt.events = append(rt.events, fmt.Sprintf("exit %d %s %v", id, methodName, results))
+}
+
+// Test global and per-object tracers.
+func TestTracer(t *testing.T) {
+	obj := goop.New()
+	obj.Set("double", func(this goop.Object, x int) int { return 2 * x })
+	obj.Set("quadruple", func(this goop.Object, x int) int {
+		return this.Call("double", this.Call("double", x)[0])[0].(int)
+	})
+	global := &recordingTracer{}
+	goop.SetTracer(global)
+	defer goop.SetTracer(nil)
+	obj.Call("quadruple", 1)
+	id := obj.ID()
+	expected := []string{
+		fmt.Sprintf("enter %d quadruple [1]", id),
+		fmt.Sprintf("enter %d double [1]", id),
+		fmt.Sprintf("exit %d double [2]", id),
+		fmt.Sprintf("enter %d double [2]", id),
+		fmt.Sprintf("exit %d double [4]", id),
+		fmt.Sprintf("exit %d quadruple [4]", id),
+	}
+	if strings.Join(global.events, "; ") != strings.Join(expected, "; ") {
+		t.Fatalf("Expected %v but saw %v", expected, global.events)
+	}
+
+	// A per-object tracer takes precedence over the global tracer.
+	local := &recordingTracer{}
+	obj.SetTracer(local)
+	global.events = nil
+	obj.CallErr("double", 5)
+	obj.CallSym(goop.Intern("double"), 6)
+	if len(global.events) != 0 || len(local.events) != 4 {
+		t.Fatalf("Expected %d global and %d local events but saw %v and %v", 0, 4, global.events, local.events)
+	}
+	obj.SetTracer(nil)
+	obj.Call("double", 7)
+	if len(global.events) != 2 {
+		t.Fatalf("Expected %d but saw %d", 2, len(global.events))
+	}
+}
+
+// Test the tracer returned by NewWriterTracer.
+func TestWriterTracer(t *testing.T) {
+	var buf bytes.Buffer
+	obj := goop.New()
+	obj.Set("add", func(this goop.Object, x, y int) int { return x + y })
+	obj.SetTracer(goop.NewWriterTracer(&buf))
+	obj.Call("add", 2, 3)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected %d lines but saw %q", 2, buf.String())
+	}
+	if expected := fmt.Sprintf("-> #%d add(2, 3)", obj.ID()); lines[0] != expected {
+		t.Fatalf("Expected %q but saw %q", expected, lines[0])
+	}
+	if expected := fmt.Sprintf("<- #%d add = [5] (", obj.ID()); !strings.HasPrefix(lines[1], expected) {
+		t.Fatalf("Expected %q... but saw %q", expected, lines[1])
+	}
+}
+
+// Test that a global writer tracer can trace the toString method it
+// invokes when formatting a result.
+func TestWriterTracerToString(t *testing.T) {
+	var buf bytes.Buffer
+	goop.SetTracer(goop.NewWriterTracer(&buf))
+	defer goop.SetTracer(nil)
+	point := goop.New()
+	point.Set("toString", func(this goop.Object) string { return "(1, 2)" })
+	obj := goop.New()
+	obj.Set("origin", func(this goop.Object) goop.Object { return point })
+	obj.Call("origin")
+	if !strings.Contains(buf.String(), "origin = [(1, 2)]") {
+		t.Fatalf("Expected %q in %q", "origin = [(1, 2)]", buf.String())
+	}
+}
@@ -0,0 +1,174 @@
+// This file implements transactions, which apply a group of member
+// changes to an object all together or not at all.
+
+package goop
+
+import "errors"
+import "fmt"
+import "strings"
+
+// A Tx buffers changes to an object's members within a call to
+// Transact.  Like an Object, a Tx is a handle that can be freely
+// copied.
+type Tx struct {
+	state *txState // Shared state of the transaction
+}
+
+// A txState holds the changes buffered by a Tx.
+type txState struct {
+	obj     Object         // Object being modified
+	changes []txChange     // Buffered changes in the order they were made
+	latest  map[string]int // Map from a member name to the index of its most recent change
+}
+
+// A txChange represents one buffered Set or Unset.
+type txChange struct {
+	name  string      // Name of the member
+	value interface{} // New value of the member (unused for an Unset)
+	unset bool        // true for an Unset, false for a Set
+}
+
+// Get is like Object.Get but reflects the changes already buffered by
+// the transaction.
+func (tx *Tx) Get(memberName string) interface{} {
+	value, ok := tx.GetOK(memberName)
+	if !ok {
+		return ErrNotFound
+	}
+	return value
+}
+
+// GetOK is like Object.GetOK but reflects the changes already buffered
+// by the transaction.
+func (tx *Tx) GetOK(memberName string) (interface{}, bool) {
+	st := tx.state
+	i, ok := st.latest[memberName]
+	switch {
+	case !ok:
+		return st.obj.GetOK(memberName)
+	case st.changes[i].unset:
+		return st.obj.getInherited(memberName)
+	default:
+		return st.changes[i].value, true
+	}
+}
+
+// Set buffers a change to the value of an object member.
+func (tx *Tx) Set(memberName string, value interface{}) {
+	tx.record(txChange{name: memberName, value: value})
+}
+
+// Unset buffers the removal of an object member.
+func (tx *Tx) Unset(memberName string) {
+	tx.record(txChange{name: memberName, unset: true})
+}
+
+// record buffers a change.
+func (tx *Tx) record(change txChange) {
+	st := tx.state
+	st.latest[change.name] = len(st.changes)
+	st.changes = append(st.changes, change)
+}
+
+// A RollbackError describes a transaction whose changes could not all
+// be applied and whose applied changes could not all be rolled back.
+// It wraps the error that prevented a change from being applied.
+// errors.Is additionally reports that a RollbackError is any of the
+// errors that prevented the rollback.
+type RollbackError struct {
+	Err      error   // Error that prevented a change from being applied
+	Rollback []error // Errors that prevented objects from being restored
+}
+
+// Error returns a description of a RollbackError.
+func (e *RollbackError) Error() string {
+	msgs := make([]string, len(e.Rollback))
+	for i, err := range e.Rollback {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%v (rollback failed: %s)", e.Err, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error that prevented a change from being applied.
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if any error that prevented the rollback is the
+// target.
+func (e *RollbackError) Is(target error) bool {
+	for _, err := range e.Rollback {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transact invokes a function that modifies the object's members via a
+// Tx.  The function's Sets and Unsets are buffered and are applied to
+// the object, in order, only if the function returns nil; otherwise,
+// they are discarded and Transact returns the function's error.  If a
+// buffered change cannot be applied (e.g., because the object is
+// frozen or a property rejects the value), the changes already applied
+// are rolled back as by Restore, including changes to shared members
+// (see SetShared) that were applied to the ancestors that own them, and
+// Transact returns the error that TrySet or TryUnset reported.  If the
+// rollback itself fails, Transact instead returns a *RollbackError.
+// Watchers are notified of each change as it is applied, even if it
+// is later rolled back.  Transact does not lock the object for the
+// duration of the function, so concurrent changes made outside the
+// transaction may be overwritten.
+func (obj *Object) Transact(function func(tx Tx) error) error {
+	st := &txState{obj: *obj, latest: make(map[string]int)}
+	if err := function(Tx{state: st}); err != nil {
+		return err
+	}
+
+	// Apply the buffered changes, snapshotting each object before its
+	// first change and rolling back on failure.
+	objs := []Object{*obj}
+	snaps := []*Snapshot{obj.Snapshot()}
+	for _, change := range st.changes {
+		var err error
+		if change.unset {
+			err = obj.TryUnset(change.name)
+		} else {
+			if owner, ok := obj.sharedOwner(change.name); ok && !containsObject(objs, owner) {
+				objs = append(objs, owner)
+				snaps = append(snaps, owner.Snapshot())
+			}
+			err = obj.TrySet(change.name, change.value)
+		}
+		if err != nil {
+			return rollback(objs, snaps, err)
+		}
+	}
+	return nil
+}
+
+// rollback restores each object from the corresponding snapshot and
+// returns err or, if any object could not be restored, a
+// *RollbackError wrapping err.
+func rollback(objs []Object, snaps []*Snapshot, err error) error {
+	var failures []error
+	for i := range objs {
+		if rErr := objs[i].Restore(snaps[i]); rErr != nil {
+			failures = append(failures, rErr)
+		}
+	}
+	if failures != nil {
+		return &RollbackError{Err: err, Rollback: failures}
+	}
+	return err
+}
+
+// containsObject returns true if a list contains an object.
+func containsObject(objs []Object, obj Object) bool {
+	for _, other := range objs {
+		if other.IsEquiv(obj) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// This file tests applying groups of member changes atomically.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test committing and discarding transactions.
+func TestTransact(t *testing.T) {
+	// Transfer funds between two members of an account.
+	acct := goop.New()
+	acct.Set("checking", 100)
+	acct.Set("savings", 0)
+	transfer := func(amount int) error {
+		return acct.Transact(func(tx goop.Tx) error {
+			tx.Set("checking", tx.Get("checking").(int)-amount)
+			tx.Set("savings", tx.Get("savings").(int)+amount)
+			if tx.Get("checking").(int) < 0 {
+				return errors.New("Insufficient funds")
+			}
+			return nil
+		})
+	}
+
+	// Ensure that a successful transaction is applied and a failed
+	// one is discarded.
+	if err := transfer(60); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if err := transfer(60); err == nil {
+		t.Fatalf("Expected the second transfer to fail")
+	}
+	if checking, savings := acct.Get("checking").(int), acct.Get("savings").(int); checking != 40 || savings != 60 {
+		t.Fatalf("Expected (40, 60) but saw (%d, %d)", checking, savings)
+	}
+
+	// Ensure that a change that cannot be applied rolls back the
+	// changes applied before it.
+	acct.DefineProperty("limit", goop.Descriptor{
+		Get: func(this goop.Object) interface{} { return 1000 },
+	})
+	err := acct.Transact(func(tx goop.Tx) error {
+		tx.Unset("savings")
+		tx.Set("limit", 5000)
+		return nil
+	})
+	if !errors.Is(err, goop.ErrReadOnly) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrReadOnly, err)
+	}
+	if !acct.HasMember("savings") {
+		t.Fatalf("Expected member %q to be restored", "savings")
+	}
+}
+
+// Test rolling back changes to shared members and failing to roll
+// back changes to a frozen object.
+func TestTransactRollback(t *testing.T) {
+	errRejected := errors.New("rejected")
+	proto := goop.New()
+	proto.SetShared("count", 0)
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.DefineProperty("checked", goop.Descriptor{
+		Set: func(this goop.Object, value interface{}) error { return errRejected },
+	})
+	err := obj.Transact(func(tx goop.Tx) error {
+		tx.Set("count", 5)
+		tx.Set("checked", 1)
+		return nil
+	})
+	if err != errRejected {
+		t.Fatalf("Expected %v but saw %v", errRejected, err)
+	}
+	if result := proto.Get("count"); result != 0 {
+		t.Fatalf("Expected %d but saw %v", 0, result)
+	}
+
+	// Freeze the object before the transaction fails.
+	obj.DefineProperty("freezer", goop.Descriptor{
+		Set: func(this goop.Object, value interface{}) error {
+			this.Freeze()
+			return errRejected
+		},
+	})
+	err = obj.Transact(func(tx goop.Tx) error {
+		tx.Set("x", 1)
+		tx.Set("freezer", 1)
+		return nil
+	})
+	var rbErr *goop.RollbackError
+	if !errors.As(err, &rbErr) || !errors.Is(err, errRejected) || !errors.Is(err, goop.ErrFrozen) {
+		t.Fatalf("Expected a RollbackError but saw %v", err)
+	}
+}
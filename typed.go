@@ -0,0 +1,118 @@
+// This file provides type-safe access to object members.
+
+package goop
+
+import "fmt"
+import "reflect"
+
+// A TypeError describes an attempt to retrieve an object member as a
+// type other than the member's dynamic type.  It wraps
+// ErrTypeMismatch.
+type TypeError struct {
+	Member   string       // Name of the member (or method, for a method's result)
+	Expected reflect.Type // Type requested by the caller
+	Actual   reflect.Type // Dynamic type of the member's value (nil for a nil value)
+}
+
+// Error returns a description of a TypeError.
+func (e *TypeError) Error() string {
+	actual := "nil"
+	if e.Actual != nil {
+		actual = e.Actual.String()
+	}
+	return fmt.Sprintf("Member %q: %s: expected %s but saw %s",
+		e.Member, ErrTypeMismatch, e.Expected, actual)
+}
+
+// Unwrap returns ErrTypeMismatch.
+func (e *TypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+// GetAs returns the value associated with the name of an object member
+// as a value of type T.  It returns a *NotFoundError if the member
+// does not exist and a *TypeError if the member's value is not of type
+// T.  A nil value is returned as the zero value of T if T is a type
+// that accepts nil.
+func GetAs[T any](obj Object, memberName string) (T, error) {
+	var zero T
+	value, ok := obj.GetOK(memberName)
+	if !ok {
+		return zero, &NotFoundError{Member: memberName}
+	}
+	return convertTo[T](memberName, value)
+}
+
+// convertTo returns the value of a named member or method result as a
+// value of type T or a *TypeError if the value is not of type T.  A
+// nil value is converted to the zero value of T if T is a type that
+// accepts nil.
+func convertTo[T any](name string, value interface{}) (T, error) {
+	var zero T
+	if result, ok := value.(T); ok {
+		return result, nil
+	}
+	expected := reflect.TypeOf((*T)(nil)).Elem()
+	if value == nil && acceptsNil(expected) {
+		return zero, nil
+	}
+	return zero, &TypeError{
+		Member:   name,
+		Expected: expected,
+		Actual:   reflect.TypeOf(value),
+	}
+}
+
+// MustGetAs is like GetAs but panics instead of returning an error.
+func MustGetAs[T any](obj Object, memberName string) T {
+	result, err := GetAs[T](obj, memberName)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// CallAs1 invokes a method that returns a single value, as with Call1,
+// and returns that value as a value of type T.  In addition to the
+// errors returned by Call1, CallAs1 returns a *TypeError if the
+// method's result is not of type T.
+func CallAs1[T any](obj Object, methodName string, arguments ...interface{}) (T, error) {
+	var zero T
+	value, err := obj.Call1(methodName, arguments...)
+	if err != nil {
+		return zero, err
+	}
+	return convertTo[T](methodName, value)
+}
+
+// SetTyped constrains the named member of the object to values
+// assignable to a given type.  Subsequent attempts to assign any other
+// value with Set, TrySet, or SetSym fail with a *TypeError: Set and
+// SetSym panic, and TrySet returns the error.  nil is accepted if the
+// type accepts nil.  SetTyped does not check the member's current
+// value.  The constraint becomes a Field of the Schema the object
+// enforces (see Enforce), so it applies only to the object itself, not
+// to its descendants, and Enforce replaces it.  Passing a nil type
+// removes the constraint.
+func (obj *Object) SetTyped(memberName string, memberType reflect.Type) {
+	impl := obj.Implementation
+	for {
+		old := impl.schema.Load()
+		var schema Schema
+		if old != nil {
+			schema = append(schema, *old...)
+		}
+		if f := schema.field(memberName); f != nil {
+			f.Type = memberType
+		} else if memberType != nil {
+			schema = append(schema, Field{Name: memberName, Type: memberType})
+		}
+		var next *Schema
+		if len(schema) > 0 {
+			next = &schema
+		}
+		if impl.schema.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
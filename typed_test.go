@@ -0,0 +1,102 @@
+// This file tests type-safe access to object members.
+
+package goop_test
+
+import (
+	"errors"
+	"github.com/lanl/goop"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test retrieving members as values of a given type.
+func TestGetAs(t *testing.T) {
+	obj := goop.New()
+	obj.Set("x", 5)
+	obj.Set("writer", nil)
+
+	// Ensure that members of the expected type are returned.
+	if result, err := goop.GetAs[int](obj, "x"); err != nil || result != 5 {
+		t.Fatalf("Expected (%d, nil) but saw (%v, %v)", 5, result, err)
+	}
+	if result, err := goop.GetAs[io.Writer](obj, "writer"); err != nil || result != nil {
+		t.Fatalf("Expected (nil, nil) but saw (%v, %v)", result, err)
+	}
+
+	// Ensure that missing and mistyped members produce errors.
+	if _, err := goop.GetAs[int](obj, "y"); !errors.Is(err, goop.ErrNotFound) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, err)
+	}
+	_, err := goop.GetAs[string](obj, "x")
+	var typeErr *goop.TypeError
+	if !errors.As(err, &typeErr) || !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected a TypeError but saw %v", err)
+	}
+	if !strings.Contains(err.Error(), "string") || !strings.Contains(err.Error(), "int") {
+		t.Fatalf("Expected %q to name both types", err.Error())
+	}
+
+	// Ensure that MustGetAs panics on failure.
+	if result := goop.MustGetAs[int](obj, "x"); result != 5 {
+		t.Fatalf("Expected %d but saw %v", 5, result)
+	}
+	defer func() {
+		if r, ok := recover().(*goop.TypeError); !ok || r.Member != "x" {
+			t.Fatalf("Expected a TypeError but saw %v", r)
+		}
+	}()
+	goop.MustGetAs[float64](obj, "x")
+}
+
+// Test calling methods whose results are of a given type.
+func TestCallAs1(t *testing.T) {
+	obj := goop.New()
+	obj.Set("half", func(self goop.Object, x float64) float64 { return x / 2 })
+	if result, err := goop.CallAs1[float64](obj, "half", 5.0); err != nil || result != 2.5 {
+		t.Fatalf("Expected (%.1f, nil) but saw (%v, %v)", 2.5, result, err)
+	}
+	if _, err := goop.CallAs1[int](obj, "half", 5.0); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+	if _, err := goop.CallAs1[float64](obj, "bogus"); !errors.Is(err, goop.ErrNotFound) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrNotFound, err)
+	}
+}
+
+// Test constraining members to values of a given type.
+func TestSetTyped(t *testing.T) {
+	obj := goop.New()
+	obj.SetTyped("x", reflect.TypeOf(0))
+	obj.SetTyped("writer", reflect.TypeOf((*io.Writer)(nil)).Elem())
+	obj.Set("x", 5)
+	obj.Set("writer", nil)
+	obj.Set("writer", &strings.Builder{})
+	err := obj.TrySet("x", "five")
+	var typeErr *goop.TypeError
+	if !errors.As(err, &typeErr) || typeErr.Member != "x" {
+		t.Fatalf("Expected a TypeError but saw %v", err)
+	}
+	if result := obj.Get("x"); result != 5 {
+		t.Fatalf("Expected %d but saw %v", 5, result)
+	}
+	if err := obj.TrySet("writer", 5); !errors.Is(err, goop.ErrTypeMismatch) {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+
+	// Ensure that descendants are unconstrained.
+	child := goop.New()
+	child.SetSuper(obj)
+	child.Set("x", "five")
+
+	// Remove the constraint.
+	obj.SetTyped("x", nil)
+	obj.Set("x", "five")
+	defer func() {
+		if r, ok := recover().(*goop.TypeError); !ok || r.Member != "writer" {
+			t.Fatalf("Expected a TypeError but saw %v", r)
+		}
+	}()
+	obj.SetSym(goop.Intern("writer"), 5)
+}
@@ -0,0 +1,43 @@
+// This file implements watchers, which are notified whenever an object
+// member changes.
+
+package goop
+
+// A watcher represents a callback registered on an object member.
+type watcher struct {
+	callback func(old, new interface{}) // Function to invoke on a change
+}
+
+// Watch registers a callback to be invoked after the named member of
+// the object is changed by Set, Add, or Unset.  The callback receives
+// the member's previous and new values.  The previous value is
+// ErrNotFound if the object did not previously contain the member
+// (even if it inherited it from a prototype), and the new value is
+// ErrNotFound if the member was Unset.  Watchers observe only the
+// object on which they are registered, not its prototypes or
+// descendants.  The callback is invoked without any locks held, so it
+// may freely access the object.  Watch returns a function that
+// unregisters the callback.
+func (obj *Object) Watch(memberName string, callback func(old, new interface{})) (cancel func()) {
+	w := &watcher{callback: callback}
+	impl := obj.Implementation
+	impl.lock.Lock()
+	if impl.watchers == nil {
+		impl.watchers = make(map[string][]*watcher)
+	}
+	impl.watchers[memberName] = appendItem(impl.watchers[memberName], w)
+	impl.lock.Unlock()
+	return func() {
+		impl.lock.Lock()
+		removeItem(impl.watchers, memberName, w)
+		impl.lock.Unlock()
+	}
+}
+
+// notifyWatchers invokes each watcher's callback on a member's old and
+// new values.  The caller must not hold the object's lock.
+func notifyWatchers(watchers []*watcher, old, new interface{}) {
+	for _, w := range watchers {
+		w.callback(old, new)
+	}
+}
@@ -0,0 +1,50 @@
+// This file tests watching object members for changes.
+
+package goop_test
+
+import (
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// Test notifying watchers of changes to an object member.
+func TestWatch(t *testing.T) {
+	// Watch a member of a child object.
+	parent := goop.New()
+	parent.Set("x", 1)
+	child := goop.New()
+	child.SetSuper(parent)
+	var changes [][2]interface{}
+	cancel := child.Watch("x", func(old, new interface{}) {
+		changes = append(changes, [2]interface{}{old, new})
+	})
+
+	// Ensure that Set, Add, and Unset notify the watcher but changes
+	// to the parent and to other members do not.
+	child.Set("x", 2)
+	child.Add("x", 3)
+	child.Set("y", 4)
+	parent.Set("x", 5)
+	child.Unset("x")
+	child.Unset("x")
+	expected := [][2]interface{}{
+		{goop.ErrNotFound, 2},
+		{2, 5},
+		{5, goop.ErrNotFound},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes but saw %d", len(expected), len(changes))
+	}
+	for i, change := range expected {
+		if changes[i] != change {
+			t.Fatalf("Expected %v but saw %v", change, changes[i])
+		}
+	}
+
+	// Ensure that a canceled watcher is no longer notified.
+	cancel()
+	child.Set("x", 6)
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes but saw %d", len(expected), len(changes))
+	}
+}
@@ -0,0 +1,215 @@
+// This file provides support for exposing ordinary Go structs as
+// objects.
+
+package goop
+
+import "fmt"
+import "reflect"
+import "sync"
+
+// A structField represents a member whose value is stored in a field
+// of a wrapped struct rather than in the object itself.
+type structField struct {
+	field reflect.Value // Addressable struct field
+	lock  *sync.RWMutex // Lock shared by all fields of the same struct
+}
+
+// get returns the field's current value.
+func (f *structField) get() interface{} {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.field.Interface()
+}
+
+// set assigns a value to the field.  It returns ErrTypeMismatch if the
+// value is not assignable to the field.
+func (f *structField) set(value interface{}) error {
+	var val reflect.Value
+	switch {
+	case value == nil && acceptsNil(f.field.Type()):
+		val = reflect.Zero(f.field.Type())
+	case value != nil && reflect.TypeOf(value).AssignableTo(f.field.Type()):
+		val = reflect.ValueOf(value)
+	default:
+		return ErrTypeMismatch
+	}
+	f.lock.Lock()
+	f.field.Set(val)
+	f.lock.Unlock()
+	return nil
+}
+
+// Wrap returns an object whose members are the exported fields and
+// methods of a struct, which must be passed by pointer.  Getting a
+// field member returns the field's current value, and Setting a field
+// member assigns to the field, panicking with ErrTypeMismatch if the
+// value is not assignable to the field's type.  Methods are invoked
+// via Call like any other method functions but are not passed the
+// object.  Unexported fields are ignored.  Wrap panics if its argument
+// is not a pointer to a struct.
+func Wrap(structPtr interface{}) Object {
+	ptrVal := reflect.ValueOf(structPtr)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() || ptrVal.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("Wrap requires a non-nil pointer to a struct, not %T", structPtr))
+	}
+	obj := newObject()
+	impl := obj.Implementation
+	structVal := ptrVal.Elem()
+	structType := structVal.Type()
+	lock := &sync.RWMutex{}
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).PkgPath != "" {
+			continue // Unexported field
+		}
+		impl.symbolTable.put(structType.Field(i).Name, &structField{
+			field: structVal.Field(i),
+			lock:  lock,
+		})
+	}
+	ptrType := ptrVal.Type()
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		impl.symbolTable.put(ptrType.Method(i).Name, ptrVal.Method(i).Interface())
+	}
+	return obj
+}
+
+// FromStruct returns an object whose members are the exported fields
+// and methods of a struct.  If its argument is a pointer to a struct,
+// FromStruct behaves like Wrap: field members read and write the
+// struct's fields, and all methods, including those with pointer
+// receivers, act on the struct itself.  If its argument is a struct
+// value, FromStruct instead copies each exported field into an
+// ordinary data member and exposes the methods with value receivers,
+// bound to a private copy of the struct.  Writes to such an object
+// therefore do not propagate back to the struct.  FromStruct panics if
+// its argument is neither a struct nor a non-nil pointer to a struct.
+func FromStruct(s interface{}) Object {
+	structVal := reflect.ValueOf(s)
+	if structVal.Kind() == reflect.Ptr {
+		return Wrap(s)
+	}
+	if structVal.Kind() != reflect.Struct {
+		panic(fmt.Errorf("FromStruct requires a struct or a pointer to a struct, not %T", s))
+	}
+	obj := newObject()
+	impl := obj.Implementation
+	structType := structVal.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).PkgPath != "" {
+			continue // Unexported field
+		}
+		impl.symbolTable.put(structType.Field(i).Name, structVal.Field(i).Interface())
+	}
+	for i := 0; i < structType.NumMethod(); i++ {
+		impl.symbolTable.put(structType.Method(i).Name, structVal.Method(i).Interface())
+	}
+	return obj
+}
+
+// ToStruct assigns to each exported field of a struct, which must be
+// passed by pointer, the value of the object member with the same
+// name, including members inherited from prototypes.  A struct tag of
+// the form `goop:"name"` maps a field to a differently named member,
+// and `goop:"-"` excludes a field.  Fields with no corresponding
+// member are left unmodified.  A member's value is assigned directly
+// if possible, converted as by a Go conversion if both it and the
+// field are numeric or share an underlying kind, and, if it is an
+// Object and the field is a struct or a pointer to a struct, converted
+// recursively by ToStruct.  ToStruct returns a *TypeError if a
+// member's value cannot be assigned to its field, and it panics if its
+// argument is not a non-nil pointer to a struct.
+func (obj *Object) ToStruct(structPtr interface{}) error {
+	ptrVal := reflect.ValueOf(structPtr)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() || ptrVal.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("ToStruct requires a non-nil pointer to a struct, not %T", structPtr))
+	}
+	structVal := ptrVal.Elem()
+	structType := structVal.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue // Unexported field
+		}
+		memberName := field.Name
+		if tag, ok := field.Tag.Lookup("goop"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				memberName = tag
+			}
+		}
+		value, ok := obj.GetOK(memberName)
+		if !ok {
+			continue
+		}
+		if err := assignField(structVal.Field(i), value); err != nil {
+			if err == ErrTypeMismatch {
+				return &TypeError{Member: memberName, Expected: field.Type, Actual: reflect.TypeOf(value)}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// assignField assigns a member's value to a struct field as described
+// by ToStruct.  It returns ErrTypeMismatch if the value cannot be
+// assigned to the field.
+func assignField(field reflect.Value, value interface{}) error {
+	fieldType := field.Type()
+	if value == nil {
+		if !acceptsNil(fieldType) {
+			return ErrTypeMismatch
+		}
+		field.Set(reflect.Zero(fieldType))
+		return nil
+	}
+	val := reflect.ValueOf(value)
+	valType := val.Type()
+	switch {
+	case valType.AssignableTo(fieldType):
+		field.Set(val)
+	case isNumeric(valType) && isNumeric(fieldType),
+		valType.Kind() == fieldType.Kind() && valType.ConvertibleTo(fieldType):
+		field.Set(val.Convert(fieldType))
+	case valType == objectType && fieldType.Kind() == reflect.Struct:
+		sub := value.(Object)
+		return sub.ToStruct(field.Addr().Interface())
+	case valType == objectType && fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct:
+		ptr := reflect.New(fieldType.Elem())
+		sub := value.(Object)
+		if err := sub.ToStruct(ptr.Interface()); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	default:
+		return ErrTypeMismatch
+	}
+	return nil
+}
+
+// isNumeric returns true if a type is an integer or floating-point
+// type.
+func isNumeric(t reflect.Type) bool {
+	class, _ := numericClass(t)
+	return class != 0
+}
+
+// Adopt defines in the object a method for each exported method of a
+// Go value, bound to that value.  As with Wrap, the methods are not
+// passed the object.  Adopted methods are ordinary members, so a later
+// Set can override any of them for this object alone, and they can be
+// inherited by the object's descendants.  Pass a pointer to adopt
+// methods with pointer receivers.  Adopt panics under the same
+// conditions as Set.
+func (obj *Object) Adopt(value interface{}) {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		panic(fmt.Errorf("Adopt requires a non-nil value"))
+	}
+	valType := val.Type()
+	for i := 0; i < valType.NumMethod(); i++ {
+		obj.Set(valType.Method(i).Name, val.Method(i).Interface())
+	}
+}
@@ -0,0 +1,150 @@
+// This file tests exposing ordinary Go structs as objects.
+
+package goop_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lanl/goop"
+	"testing"
+)
+
+// An Account is an ordinary Go struct to wrap as an object.
+type Account struct {
+	Owner   string
+	Balance int
+	secret  string
+}
+
+// Deposit adds an amount to the account's balance.
+func (a *Account) Deposit(amount int) {
+	a.Balance += amount
+}
+
+// String describes the account.
+func (a Account) String() string {
+	return fmt.Sprintf("%s: %d", a.Owner, a.Balance)
+}
+
+// Test wrapping a struct as an object.
+func TestWrap(t *testing.T) {
+	acct := &Account{Owner: "Alice", Balance: 100, secret: "hidden"}
+	obj := goop.Wrap(acct)
+
+	// Ensure that exported fields and methods are exposed and
+	// unexported fields are not.
+	if obj.HasMember("secret") {
+		t.Fatalf("Unexpectedly found member %q", "secret")
+	}
+	obj.Call("Deposit", 50)
+	if result := obj.Get("Balance").(int); result != 150 {
+		t.Fatalf("Expected %d but saw %v", 150, result)
+	}
+
+	// Ensure that Set writes through to the struct.
+	obj.Set("Owner", "Bob")
+	if acct.Owner != "Bob" {
+		t.Fatalf("Expected %q but saw %q", "Bob", acct.Owner)
+	}
+	if result := obj.Call("String")[0].(string); result != "Bob: 150" {
+		t.Fatalf("Expected %q but saw %v", "Bob: 150", result)
+	}
+	if err := obj.TrySet("Balance", "lots"); err != goop.ErrTypeMismatch {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+
+	// Ensure that non-pointer arguments are rejected.
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected Wrap to panic on a non-pointer argument")
+		}
+	}()
+	goop.Wrap(*acct)
+}
+
+// Test converting structs and pointers to structs to objects.
+func TestFromStruct(t *testing.T) {
+	// Ensure that a struct value is copied.
+	acct := Account{Owner: "Alice", Balance: 100, secret: "hidden"}
+	obj := goop.FromStruct(acct)
+	if obj.HasMember("secret") || obj.HasMember("Deposit") {
+		t.Fatalf("Unexpectedly found an unexported field or pointer method")
+	}
+	obj.Set("Balance", 200)
+	if acct.Balance != 100 {
+		t.Fatalf("Expected %d but saw %d", 100, acct.Balance)
+	}
+	if result := obj.Call("String")[0].(string); result != "Alice: 100" {
+		t.Fatalf("Expected %q but saw %v", "Alice: 100", result)
+	}
+
+	// Ensure that a pointer to a struct is wrapped.
+	obj = goop.FromStruct(&acct)
+	obj.Call("Deposit", 25)
+	obj.Set("Owner", "Bob")
+	if acct.Balance != 125 || acct.Owner != "Bob" {
+		t.Fatalf("Expected %q but saw %v", "Bob: 125", acct)
+	}
+}
+
+// A Shipment is an ordinary Go struct to populate from an object.
+type Shipment struct {
+	Weight float64
+	Count  uint8
+	Dest   *Address `goop:"destination"`
+	Notes  string   `goop:"-"`
+}
+
+// An Address is nested within a Shipment.
+type Address struct {
+	City string
+}
+
+// Test populating a struct from an object's members.
+func TestToStruct(t *testing.T) {
+	// Define an object whose members require conversion and
+	// inheritance.
+	proto := goop.New()
+	proto.Set("Weight", 12)
+	obj := goop.New()
+	obj.SetSuper(proto)
+	obj.Set("Count", 3)
+	obj.Set("Notes", "ignored")
+	dest := goop.New()
+	dest.Set("City", "Los Alamos")
+	obj.Set("destination", dest)
+
+	// Ensure that all fields are populated as expected.
+	var s Shipment
+	if err := obj.ToStruct(&s); err != nil {
+		t.Fatalf("Expected %v but saw %v", nil, err)
+	}
+	if s.Weight != 12.0 || s.Count != 3 || s.Notes != "" {
+		t.Fatalf("Expected %v but saw %v", Shipment{Weight: 12, Count: 3}, s)
+	}
+	if s.Dest == nil || s.Dest.City != "Los Alamos" {
+		t.Fatalf("Expected %q but saw %v", "Los Alamos", s.Dest)
+	}
+
+	// Ensure that an inconvertible member is reported.
+	obj.Set("Count", "three")
+	var typeErr *goop.TypeError
+	if err := obj.ToStruct(&s); !errors.As(err, &typeErr) || typeErr.Member != "Count" {
+		t.Fatalf("Expected %v but saw %v", goop.ErrTypeMismatch, err)
+	}
+}
+
+// Test adopting a Go value's methods and then overriding one.
+func TestAdopt(t *testing.T) {
+	acct := &Account{Owner: "Alice", Balance: 100}
+	obj := goop.New()
+	obj.Adopt(acct)
+	obj.Call("Deposit", 20)
+	if acct.Balance != 120 {
+		t.Fatalf("Expected %d but saw %d", 120, acct.Balance)
+	}
+	obj.Set("String", func(this goop.Object) string { return "overridden" })
+	if result := obj.Call("String")[0].(string); result != "overridden" {
+		t.Fatalf("Expected %q but saw %q", "overridden", result)
+	}
+}